@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/config"
+	"github.com/johndosdos/chirpy/internal/database"
+)
+
+const chirpsUsage = `usage: chirpy chirps export [flags]
+
+  export  write published chirps as a JSON array, oldest first. --all
+          adds hidden and scheduled ones, told apart by hidden_at and
+          status
+`
+
+// runChirps implements "chirpy chirps".
+func runChirps(ctx context.Context, args []string, out io.Writer) error {
+	action, args, err := subcommand(args, chirpsUsage)
+	if err != nil {
+		return err
+	}
+	if action != "export" {
+		return fmt.Errorf("unknown chirps command %q", action)
+	}
+
+	fset := flag.NewFlagSet("chirpy chirps export", flag.ContinueOnError)
+	loader := config.NewLoader(fset)
+	email := fset.String("email", "", "only export chirps by this user")
+	output := fset.String("output", "", "file to write to instead of stdout")
+	all := fset.Bool("all", false, "include chirps hidden by moderators and scheduled chirps")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := openDB(loader)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	queries := database.New(db)
+
+	var chirps []database.Chirp
+	if *email != "" {
+		user, err := queries.GetUserByEmail(ctx, *email)
+		if err != nil {
+			return fmt.Errorf("no user with email %q: %w", *email, err)
+		}
+		chirps, err = queries.GetChirpsByUser(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+	} else {
+		chirps, err = queries.GetChirps(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	// an empty export is [], not null.
+	exported := []exportedChirp{}
+	for _, chirp := range chirps {
+		if *all || (chirp.Status == "published" && !chirp.HiddenAt.Valid) {
+			exported = append(exported, newExportedChirp(chirp))
+		}
+	}
+
+	if *output == "" {
+		return writeChirps(out, exported)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := writeChirps(f, exported); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "exported %d chirp(s) to %s\n", len(exported), *output)
+	return nil
+}
+
+// exportedChirp has the fields of a chirp in the API, plus hidden_at.
+type exportedChirp struct {
+	ID            uuid.UUID  `json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Body          string     `json:"body"`
+	UserID        uuid.UUID  `json:"user_id"`
+	EditedAt      *time.Time `json:"edited_at"`
+	Status        string     `json:"status"`
+	PublishAt     *time.Time `json:"publish_at"`
+	HiddenAt      *time.Time `json:"hidden_at"`
+	QuotedChirpID *uuid.UUID `json:"quoted_chirp_id"`
+}
+
+func newExportedChirp(chirp database.Chirp) exportedChirp {
+	res := exportedChirp{
+		ID:        chirp.ID,
+		CreatedAt: chirp.CreatedAt,
+		UpdatedAt: chirp.UpdatedAt,
+		Body:      chirp.Body,
+		UserID:    chirp.UserID,
+		EditedAt:  nullTime(chirp.EditedAt),
+		Status:    chirp.Status,
+		PublishAt: nullTime(chirp.PublishAt),
+		HiddenAt:  nullTime(chirp.HiddenAt),
+	}
+	if chirp.QuotedChirpID.Valid {
+		res.QuotedChirpID = &chirp.QuotedChirpID.UUID
+	}
+	return res
+}
+
+// nullTime turns a nullable column into a pointer, which encodes as null
+// instead of sql.NullTime's {"Time": ..., "Valid": ...}.
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func writeChirps(w io.Writer, chirps []exportedChirp) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(chirps)
+}
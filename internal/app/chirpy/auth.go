@@ -0,0 +1,59 @@
+package chirpy
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/auth"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// Authenticate validates the access token in the Authorization header and
+// returns the user it belongs to. it writes a 401 and returns false if the
+// token is missing or invalid, and a 403 if the account was suspended or
+// scheduled for deletion after the token was issued; access tokens live
+// for an hour, so that's checked against the database every time.
+func (cfg *ApiConfig) Authenticate(w http.ResponseWriter, r *http.Request) (database.User, bool) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		logging.FromContext(r.Context()).Warn("invalid authorization header", "err", err)
+		problem.Error(w, r, http.StatusUnauthorized, problem.Unauthorized, "Missing or invalid access token.")
+		return database.User{}, false
+	}
+
+	userID, err := auth.ValidateJWT(tokenString, cfg.Secret)
+	if err != nil {
+		logging.FromContext(r.Context()).Warn("invalid access token", "err", err)
+		problem.Error(w, r, http.StatusUnauthorized, problem.Unauthorized, "Missing or invalid access token.")
+		return database.User{}, false
+	}
+	logging.SetUserID(r.Context(), userID)
+
+	user, err := cfg.DB.GetUserByID(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logging.FromContext(r.Context()).Warn("token for unknown user", "user_id", userID)
+			problem.Error(w, r, http.StatusUnauthorized, problem.Unauthorized, "Missing or invalid access token.")
+		} else {
+			logging.FromContext(r.Context()).Error("failed to get user", "err", err)
+			problem.InternalError(w, r)
+		}
+		return database.User{}, false
+	}
+
+	if user.SuspendedAt.Valid {
+		logging.FromContext(r.Context()).Warn("request rejected", "reason", "suspended")
+		problem.Error(w, r, http.StatusForbidden, problem.AccountSuspended, "This account is suspended.")
+		return database.User{}, false
+	}
+	if user.DeleteAfter.Valid {
+		logging.FromContext(r.Context()).Warn("request rejected", "reason", "pending_deletion")
+		problem.Error(w, r, http.StatusForbidden, problem.Forbidden, "This account is scheduled for deletion. Log in again to restore it.")
+		return database.User{}, false
+	}
+
+	return user, true
+}
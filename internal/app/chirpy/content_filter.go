@@ -0,0 +1,25 @@
+package chirpy
+
+import (
+	"context"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy/filter"
+	"github.com/johndosdos/chirpy/internal/database"
+)
+
+// Filters builds the filter pipeline chirps go through from the rules in
+// the database. rules are read on every call, so changes made through
+// /admin/filter/rules apply right away on every replica.
+func Filters(ctx context.Context, q *database.Queries) (filter.Pipeline, error) {
+	rows, err := q.ListFilterRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]filter.Rule, len(rows))
+	for i, row := range rows {
+		rules[i] = filter.Rule{ID: row.ID.String(), Word: row.Word, Action: filter.Action(row.Action)}
+	}
+
+	return filter.Pipeline{filter.NewWordList(rules)}, nil
+}
@@ -0,0 +1,242 @@
+// Package filter checks chirp bodies against word lists. a body goes
+// through a Pipeline of filters; each one can mask words, reject the chirp
+// outright or flag it for a moderator to look at.
+package filter
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Action is what happens when a rule matches.
+type Action string
+
+const (
+	// Mask replaces the word with asterisks and lets the chirp through.
+	Mask Action = "mask"
+	// Reject refuses the chirp.
+	Reject Action = "reject"
+	// Flag lets the chirp through unchanged but records it for review.
+	Flag Action = "flag"
+)
+
+// Actions lists every valid action, e.g. for validation.
+var Actions = []Action{Mask, Reject, Flag}
+
+func (a Action) Valid() bool {
+	switch a {
+	case Mask, Reject, Flag:
+		return true
+	}
+	return false
+}
+
+// MaskString replaces masked words, whatever their length, like the
+// original profanity filter did.
+const MaskString = "****"
+
+// Match is one word that matched a rule.
+type Match struct {
+	Rule Rule
+	// Text is the word as written in the body, e.g. "K3rfuffle".
+	Text string
+}
+
+// Result is what a Pipeline did to a body.
+type Result struct {
+	Body string
+	// Rejected and Flagged list the matches of reject and flag rules.
+	// masked words are already gone from Body.
+	Rejected []Match
+	Flagged  []Match
+}
+
+// Filter is one stage of a Pipeline.
+type Filter interface {
+	// Filter returns body with masked words replaced, and the matches of
+	// reject and flag rules.
+	Filter(body string) Result
+}
+
+// Pipeline runs filters in order, each one on the body the previous one
+// returned.
+type Pipeline []Filter
+
+func (p Pipeline) Filter(body string) Result {
+	res := Result{Body: body}
+	for _, f := range p {
+		next := f.Filter(res.Body)
+		res.Body = next.Body
+		res.Rejected = append(res.Rejected, next.Rejected...)
+		res.Flagged = append(res.Flagged, next.Flagged...)
+	}
+	return res
+}
+
+// Rule is one entry of a word list. ID is opaque to this package; callers
+// use it to tell which stored rule matched.
+type Rule struct {
+	ID     string
+	Word   string
+	Action Action
+}
+
+// WordList matches whole words, ignoring case, compatibility forms (e.g.
+// fullwidth letters) and common leetspeak substitutions. punctuation around
+// a word doesn't stop it from matching, and everything that isn't a
+// matched word, spacing included, is left as is.
+type WordList struct {
+	rules map[string]Rule
+}
+
+// NewWordList builds a WordList. words are normalized like the bodies they
+// are matched against, so "Kerfuffle" and "k3rfuffle" are the same rule;
+// if two rules end up the same, the last one wins.
+func NewWordList(rules []Rule) *WordList {
+	l := &WordList{rules: make(map[string]Rule, len(rules))}
+	for _, rule := range rules {
+		if word := Normalize(rule.Word); word != "" {
+			l.rules[word] = rule
+		}
+	}
+	return l
+}
+
+func (l *WordList) Filter(body string) Result {
+	res := Result{}
+	if len(l.rules) == 0 {
+		res.Body = body
+		return res
+	}
+
+	var b strings.Builder
+	b.Grow(len(body))
+
+	last := 0
+	for _, span := range words(body) {
+		start, end, rule, ok := l.match(body[span[0]:span[1]])
+		if !ok {
+			continue
+		}
+		start, end = span[0]+start, span[0]+end
+		m := Match{Rule: rule, Text: body[start:end]}
+
+		switch rule.Action {
+		case Mask:
+			b.WriteString(body[last:start])
+			b.WriteString(MaskString)
+			last = end
+		case Reject:
+			res.Rejected = append(res.Rejected, m)
+		case Flag:
+			res.Flagged = append(res.Flagged, m)
+		}
+	}
+	b.WriteString(body[last:])
+
+	res.Body = b.String()
+	return res
+}
+
+// match checks word, and failing that word without the leet symbols at
+// either end, so "$harbert" matches but so does the mention "@fornax".
+// start and end are the part of word that matched.
+func (l *WordList) match(word string) (start, end int, rule Rule, ok bool) {
+	if rule, ok := l.rules[Normalize(word)]; ok {
+		return 0, len(word), rule, true
+	}
+
+	trimmed := strings.TrimLeft(word, leetSymbols)
+	start = len(word) - len(trimmed)
+	trimmed = strings.TrimRight(trimmed, leetSymbols)
+	end = start + len(trimmed)
+	if trimmed == "" || end-start == len(word) {
+		return 0, 0, Rule{}, false
+	}
+
+	if rule, ok := l.rules[Normalize(trimmed)]; ok {
+		return start, end, rule, true
+	}
+	return 0, 0, Rule{}, false
+}
+
+// leet maps the substitutions people use to dodge filters back to letters.
+var leet = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'@': 'a',
+	'$': 's',
+}
+
+// leetSymbols are the non-alphanumeric characters in leet. they count as
+// part of a word, since "sh@rbert" is one word.
+const leetSymbols = "@$"
+
+// Normalize is how words are compared: NFKC, lower case, leetspeak folded
+// to letters and combining marks (accents) dropped.
+func Normalize(word string) string {
+	word = norm.NFKC.String(strings.TrimSpace(word))
+
+	var b strings.Builder
+	b.Grow(len(word))
+	for _, r := range norm.NFD.String(word) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if folded, ok := leet[r]; ok {
+			r = folded
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return norm.NFC.String(b.String())
+}
+
+// words returns the byte offsets [start, end) of every word in s. a word is
+// a run of letters, digits, combining marks and leet symbols.
+func words(s string) [][2]int {
+	var spans [][2]int
+	start := -1
+	for i, r := range s {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(s)})
+	}
+	return spans
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.M, r) || strings.ContainsRune(leetSymbols, r))
+}
+
+// ValidateWord reports why word can't be a rule, or nil. rules are single
+// words, so they can't contain spaces or punctuation.
+func ValidateWord(word string) error {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return errors.New("must not be empty")
+	}
+	for _, r := range word {
+		if !isWordRune(r) {
+			return errors.New("must be a single word without punctuation")
+		}
+	}
+	return nil
+}
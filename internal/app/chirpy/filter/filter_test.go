@@ -0,0 +1,99 @@
+package filter
+
+import (
+	"testing"
+)
+
+func TestWordList(t *testing.T) {
+	list := NewWordList([]Rule{
+		{ID: "1", Word: "kerfuffle", Action: Mask},
+		{ID: "2", Word: "Sharbert", Action: Mask},
+		{ID: "3", Word: "fornax", Action: Reject},
+		{ID: "4", Word: "grift", Action: Flag},
+	})
+
+	tests := []struct {
+		body     string
+		want     string
+		rejected int
+		flagged  int
+	}{
+		{"This is a kerfuffle opinion I need to share", "This is a **** opinion I need to share", 0, 0},
+		{"what a kerfuffle!", "what a ****!", 0, 0},
+		{"KERFUFFLE,sharbert", "****,****", 0, 0},
+		// spacing survives, unlike strings.Fields.
+		{"a  kerfuffle\n\nb", "a  ****\n\nb", 0, 0},
+		// leetspeak, fullwidth letters and accents.
+		{"k3rfuffl3 and $h4rb3rt", "**** and ****", 0, 0},
+		{"ｋｅｒｆｕｆｆｌｅ", "****", 0, 0},
+		{"kérfüffle", "****", 0, 0},
+		// a mention still matches, the @ stays.
+		{"hi @sharbert", "hi @****", 0, 0},
+		// only whole words.
+		{"kerfuffles are fine", "kerfuffles are fine", 0, 0},
+		{"Fornax.", "Fornax.", 1, 0},
+		{"what a grift, kerfuffle", "what a grift, ****", 0, 1},
+	}
+
+	for _, tt := range tests {
+		res := list.Filter(tt.body)
+		if res.Body != tt.want {
+			t.Errorf("Filter(%q) = %q, want %q\n", tt.body, res.Body, tt.want)
+		}
+		if len(res.Rejected) != tt.rejected || len(res.Flagged) != tt.flagged {
+			t.Errorf("Filter(%q): %d rejected and %d flagged, want %d and %d\n", tt.body, len(res.Rejected), len(res.Flagged), tt.rejected, tt.flagged)
+		}
+	}
+
+	res := list.Filter("F0RNAX")
+	if len(res.Rejected) != 1 || res.Rejected[0].Rule.ID != "3" || res.Rejected[0].Text != "F0RNAX" {
+		t.Errorf("expected the fornax rule to match F0RNAX, got %+v\n", res.Rejected)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	p := Pipeline{
+		NewWordList([]Rule{{Word: "kerfuffle", Action: Mask}}),
+		NewWordList([]Rule{{Word: "fornax", Action: Flag}}),
+	}
+
+	res := p.Filter("kerfuffle fornax")
+	if res.Body != "**** fornax" || len(res.Flagged) != 1 {
+		t.Errorf("unexpected result %+v\n", res)
+	}
+}
+
+func TestMuter(t *testing.T) {
+	m, err := NewMuter([]MutedWord{
+		{Pattern: "spoilers", Mode: Word},
+		{Pattern: "#GameOfThrones", Mode: Word},
+		{Pattern: "breaking news", Mode: Word},
+		{Pattern: `crypto(currency)?\b`, Mode: Regex},
+	})
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	cases := map[string]bool{
+		"no SPOILERS please":          true,
+		"#spoilers ahead":             true,
+		"spoilersome":                 false,
+		"who watched #gameofthrones?": true,
+		"who watched GameOfThrones?":  false,
+		"Breaking   news: rain":       true,
+		"breaking the news":           false,
+		"Buy Cryptocurrency now":      true,
+		"cryptography is fine":        false,
+		"nothing to see here":         false,
+	}
+
+	for body, want := range cases {
+		if got := m.Mutes(body); got != want {
+			t.Errorf("Mutes(%q) = %v, want %v\n", body, got, want)
+		}
+	}
+
+	if err := ValidateMutedWord("(unclosed", Regex); err == nil {
+		t.Error("expected an invalid regex to be rejected\n")
+	}
+}
@@ -0,0 +1,166 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MatchMode is how a muted word is matched.
+type MatchMode string
+
+const (
+	// Word matches whole words or phrases, ignoring case and accents.
+	// "spoilers" also matches "#spoilers", but "#spoilers" only matches the
+	// hashtag.
+	Word MatchMode = "word"
+	// Regex matches a regular expression (RE2 syntax, so no backtracking
+	// blowups), case-insensitively.
+	Regex MatchMode = "regex"
+)
+
+// MaxMutedPatternLength keeps patterns, and the regexes they compile to,
+// small.
+const MaxMutedPatternLength = 100
+
+// MutedWord is one entry of a user's muted words.
+type MutedWord struct {
+	Pattern string
+	Mode    MatchMode
+}
+
+// Muter tells whether a chirp contains any of a user's muted words. unlike
+// a WordList it doesn't change bodies, it only hides chirps from the user
+// who muted the words.
+type Muter struct {
+	phrases [][]string
+	regexes []*regexp.Regexp
+}
+
+// NewMuter compiles words. it fails if an entry is invalid, which
+// ValidateMutedWord catches when the entry is created.
+func NewMuter(words []MutedWord) (*Muter, error) {
+	m := &Muter{}
+	for _, w := range words {
+		switch w.Mode {
+		case Word:
+			if phrase := tokens(w.Pattern); len(phrase) > 0 {
+				m.phrases = append(m.phrases, phrase)
+			}
+		case Regex:
+			re, err := regexp.Compile("(?i)" + w.Pattern)
+			if err != nil {
+				return nil, err
+			}
+			m.regexes = append(m.regexes, re)
+		default:
+			return nil, fmt.Errorf("unknown match mode %q", w.Mode)
+		}
+	}
+	return m, nil
+}
+
+// Mutes reports whether body contains any of the muted words.
+func (m *Muter) Mutes(body string) bool {
+	if m == nil {
+		return false
+	}
+
+	for _, re := range m.regexes {
+		if re.MatchString(body) {
+			return true
+		}
+	}
+
+	if len(m.phrases) == 0 {
+		return false
+	}
+	words := tokens(body)
+	for _, phrase := range m.phrases {
+		if containsPhrase(words, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateMutedWord reports why pattern can't be muted with mode, or nil.
+func ValidateMutedWord(pattern string, mode MatchMode) error {
+	if strings.TrimSpace(pattern) == "" {
+		return errors.New("must not be empty")
+	}
+	if len([]rune(pattern)) > MaxMutedPatternLength {
+		return fmt.Errorf("must be at most %d characters", MaxMutedPatternLength)
+	}
+
+	switch mode {
+	case Word:
+		if len(tokens(pattern)) == 0 {
+			return errors.New("must contain a word")
+		}
+	case Regex:
+		if _, err := regexp.Compile("(?i)" + pattern); err != nil {
+			return fmt.Errorf("must be a valid regular expression: %v", err)
+		}
+	default:
+		return errors.New("mode must be word or regex")
+	}
+	return nil
+}
+
+// containsPhrase reports whether phrase appears in words as a run.
+func containsPhrase(words, phrase []string) bool {
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		match := true
+		for j, want := range phrase {
+			if !tokenMatches(words[i+j], want) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenMatches compares a word of a body with a word of a pattern. a plain
+// pattern word matches the hashtag too, a hashtag only matches a hashtag.
+func tokenMatches(word, want string) bool {
+	if strings.HasPrefix(want, "#") {
+		return word == want
+	}
+	return strings.TrimPrefix(word, "#") == want
+}
+
+// tokens splits s into folded words. a # right before a word stays with
+// it, so hashtags can be told apart.
+func tokens(s string) []string {
+	var out []string
+	for _, span := range words(s) {
+		start := span[0]
+		if start > 0 && s[start-1] == '#' {
+			start--
+		}
+		out = append(out, fold(s[start:span[1]]))
+	}
+	return out
+}
+
+// fold is Normalize without the leetspeak folding: people muting "2024"
+// mean the year.
+func fold(word string) string {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(word) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return norm.NFC.String(b.String())
+}
@@ -0,0 +1,161 @@
+package fixtures
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/auth"
+	"github.com/johndosdos/chirpy/internal/database"
+)
+
+// fixture datasets live in data/<name>.json and are compiled into the
+// binary, so integration test suites always get the same starting state.
+//
+//go:embed data/*.json
+var dataFS embed.FS
+
+var ErrNotFound = errors.New("fixture not found")
+
+type User struct {
+	ID          uuid.UUID `json:"id"`
+	Email       string    `json:"email"`
+	Password    string    `json:"password"`
+	IsChirpyRed bool      `json:"is_chirpy_red"`
+	Handle      string    `json:"handle"`
+	DisplayName string    `json:"display_name"`
+	Bio         string    `json:"bio"`
+}
+
+type Chirp struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Dataset struct {
+	Users  []User  `json:"users"`
+	Chirps []Chirp `json:"chirps"`
+}
+
+// Names returns the names of every embedded fixture dataset.
+func Names() ([]string, error) {
+	entries, err := fs.ReadDir(dataFS, "data")
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+
+	return names, nil
+}
+
+// Get parses the fixture dataset with the given name.
+func Get(name string) (Dataset, error) {
+	file := path.Join("data", name+".json")
+	if strings.ContainsAny(name, "/\\") || !fs.ValidPath(file) {
+		return Dataset{}, ErrNotFound
+	}
+
+	raw, err := dataFS.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Dataset{}, ErrNotFound
+		}
+		return Dataset{}, err
+	}
+
+	var dataset Dataset
+	if err := json.Unmarshal(raw, &dataset); err != nil {
+		return Dataset{}, fmt.Errorf("failed to parse fixture %q: %w", name, err)
+	}
+
+	return dataset, nil
+}
+
+// Load wipes users, chirps and refresh tokens and then inserts the named
+// dataset. everything happens in a single transaction, so a failed load
+// leaves the database untouched. queries go through hooks, like the ones
+// in ApiConfig.QueryHooks.
+func Load(ctx context.Context, db *sql.DB, name string, hooks ...database.QueryHook) (Dataset, error) {
+	dataset, err := Get(name)
+	if err != nil {
+		return Dataset{}, err
+	}
+
+	// hash passwords before opening the transaction. bcrypt is slow on
+	// purpose and we don't want to hold locks while it runs.
+	hashed := make([]string, len(dataset.Users))
+	for i, user := range dataset.Users {
+		hashed[i], err = auth.HashPassword(ctx, user.Password)
+		if err != nil {
+			return Dataset{}, fmt.Errorf("failed to hash password for %s: %w", user.Email, err)
+		}
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Dataset{}, err
+	}
+	defer tx.Rollback()
+
+	q := database.New(database.Observe(tx, hooks...))
+
+	if err := q.DeleteRefreshTokens(ctx); err != nil {
+		return Dataset{}, fmt.Errorf("failed to reset refresh tokens: %w", err)
+	}
+	if err := q.DeleteChirps(ctx); err != nil {
+		return Dataset{}, fmt.Errorf("failed to reset chirps: %w", err)
+	}
+	if err := q.DeleteUsers(ctx); err != nil {
+		return Dataset{}, fmt.Errorf("failed to reset users: %w", err)
+	}
+
+	// fixtures are loaded relative to a fixed point in time so that
+	// created_at/updated_at are deterministic too.
+	for i, user := range dataset.Users {
+		_, err := q.InsertFixtureUser(ctx, database.InsertFixtureUserParams{
+			ID:             user.ID,
+			CreatedAt:      time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Email:          user.Email,
+			HashedPassword: hashed[i],
+			IsChirpyRed:    user.IsChirpyRed,
+			Handle:         user.Handle,
+			DisplayName:    user.DisplayName,
+			Bio:            user.Bio,
+		})
+		if err != nil {
+			return Dataset{}, fmt.Errorf("failed to insert user %s: %w", user.Email, err)
+		}
+	}
+
+	for _, chirp := range dataset.Chirps {
+		_, err := q.InsertFixtureChirp(ctx, database.InsertFixtureChirpParams{
+			ID:        chirp.ID,
+			CreatedAt: chirp.CreatedAt,
+			Body:      chirp.Body,
+			UserID:    chirp.UserID,
+		})
+		if err != nil {
+			return Dataset{}, fmt.Errorf("failed to insert chirp %s: %w", chirp.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Dataset{}, err
+	}
+
+	return dataset, nil
+}
@@ -0,0 +1,128 @@
+package fixtures
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/johndosdos/chirpy/internal/database"
+)
+
+// fakeConn is a database/sql driver connection that accepts every
+// statement, except queries containing failOn, and remembers how the
+// transaction ended.
+type fakeConn struct {
+	failOn     string
+	committed  bool
+	rolledBack bool
+}
+
+func (c *fakeConn) Connect(ctx context.Context) (driver.Conn, error) { return c, nil }
+func (c *fakeConn) Driver() driver.Driver                            { return nil }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *fakeConn) Commit() error             { c.committed = true; return nil }
+func (c *fakeConn) Rollback() error           { c.rolledBack = true; return nil }
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if c.failOn != "" && strings.Contains(query, c.failOn) {
+		return nil, errors.New("insert failed")
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if c.failOn != "" && strings.Contains(query, c.failOn) {
+		return nil, errors.New("insert failed")
+	}
+	return nil, errors.New("unexpected query")
+}
+
+// recordQueries returns a hook that appends the name of every query to
+// names.
+func recordQueries(names *[]string) database.QueryHook {
+	return func(ctx context.Context, name string) (context.Context, func(error)) {
+		*names = append(*names, name)
+		return ctx, func(error) {}
+	}
+}
+
+func TestLoadUnknownFixture(t *testing.T) {
+	conn := &fakeConn{}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+
+	for _, name := range []string{"does-not-exist", "../data/basic", "data/basic"} {
+		_, err := Load(context.Background(), db, name)
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: expected ErrNotFound, got %v\n", name, err)
+		}
+	}
+	if conn.committed || conn.rolledBack {
+		t.Error("expected no transaction for unknown fixtures\n")
+	}
+}
+
+func TestLoadRollsBackOnFailedInsert(t *testing.T) {
+	conn := &fakeConn{failOn: "InsertFixtureUser"}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+
+	var queries []string
+	_, err := Load(context.Background(), db, "basic", recordQueries(&queries))
+	if err == nil || !strings.Contains(err.Error(), "failed to insert user") {
+		t.Fatalf("expected insert error, got %v\n", err)
+	}
+
+	if conn.committed || !conn.rolledBack {
+		t.Errorf("expected rollback, got committed=%t rolled back=%t\n", conn.committed, conn.rolledBack)
+	}
+
+	want := []string{"DeleteRefreshTokens", "DeleteChirps", "DeleteUsers", "InsertFixtureUser"}
+	if !slices.Equal(queries, want) {
+		t.Errorf("expected queries %v through hooks, got %v\n", want, queries)
+	}
+}
+
+func TestLoadCommits(t *testing.T) {
+	conn := &fakeConn{}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+
+	var queries []string
+	dataset, err := Load(context.Background(), db, "empty", recordQueries(&queries))
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(dataset.Users) != 0 || len(dataset.Chirps) != 0 {
+		t.Errorf("expected empty dataset, got %+v\n", dataset)
+	}
+	if !conn.committed {
+		t.Error("expected commit\n")
+	}
+	if len(queries) != 3 {
+		t.Errorf("expected 3 queries through hooks, got %v\n", queries)
+	}
+}
+
+func TestDatasetsParse(t *testing.T) {
+	names, err := Names()
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	for _, name := range names {
+		if _, err := Get(name); err != nil {
+			t.Errorf("%s: %v\n", name, err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package admin
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// requireRole authenticates the request like the API does and checks that
+// the user has one of roles. the role is read from the database rather
+// than the token, so promotions and demotions apply right away. it writes
+// a 401 or 403 and returns false otherwise.
+func requireRole(cfg *chirpy.ApiConfig, w http.ResponseWriter, r *http.Request, roles ...string) (database.User, bool) {
+	user, ok := cfg.Authenticate(w, r)
+	if !ok {
+		return database.User{}, false
+	}
+
+	if !slices.Contains(roles, user.Role) {
+		logging.FromContext(r.Context()).Warn("insufficient role", "role", user.Role)
+		problem.Error(w, r, http.StatusForbidden, problem.Forbidden, "You don't have access to this.")
+		return database.User{}, false
+	}
+
+	return user, true
+}
@@ -0,0 +1,256 @@
+package admin
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/filter"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+type filterRule struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Word      string    `json:"word"`
+	Action    string    `json:"action"`
+}
+
+func newFilterRule(rule database.FilterRule) filterRule {
+	return filterRule(rule)
+}
+
+// ListFilterRules returns every rule of the chirp filter. admins only.
+func ListFilterRules(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := requireRole(cfg, w, r, "admin"); !ok {
+			return
+		}
+
+		rows, err := cfg.DB.ListFilterRules(r.Context())
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to list filter rules", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		rules := make([]filterRule, len(rows))
+		for i, row := range rows {
+			rules[i] = newFilterRule(row)
+		}
+		writeJSON(w, r, http.StatusOK, rules)
+	})
+}
+
+// CreateFilterRule adds a word to the chirp filter. the word is stored
+// normalized, e.g. "K3rfuffle" as "kerfuffle". admins only.
+func CreateFilterRule(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type request struct {
+			Word   string `json:"word"`
+			Action string `json:"action"`
+		}
+
+		if _, ok := requireRole(cfg, w, r, "admin"); !ok {
+			return
+		}
+
+		var req request
+		if !validate.DecodeJSON(w, r, &req) {
+			return
+		}
+
+		var v validate.Validator
+		if err := filter.ValidateWord(req.Word); err != nil {
+			v.Add("word", "invalid", err.Error())
+		}
+		validateAction(&v, req.Action)
+		if !v.Valid() {
+			logging.FromContext(r.Context()).Info("invalid filter rule", "errors", v.Errors)
+			v.Write(w, r)
+			return
+		}
+
+		rule, err := cfg.DB.CreateFilterRule(r.Context(), database.CreateFilterRuleParams{
+			Word:   filter.Normalize(req.Word),
+			Action: req.Action,
+		})
+		if err != nil {
+			if database.IsUniqueViolation(err, database.FilterRulesWordKey) {
+				logging.FromContext(r.Context()).Info("filter rule exists", "word", req.Word)
+				problem.Fields(w, r, "There's already a rule for this word.", problem.FieldError{
+					Field:   "word",
+					Code:    "taken",
+					Message: "already has a rule",
+				})
+				return
+			}
+			logging.FromContext(r.Context()).Error("failed to create filter rule", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		logging.FromContext(r.Context()).Info("filter rule created", "rule_id", rule.ID, "word", rule.Word, "action", rule.Action)
+		writeJSON(w, r, http.StatusCreated, newFilterRule(rule))
+	})
+}
+
+// UpdateFilterRule changes what a rule does. to change the word, delete the
+// rule and create a new one. admins only.
+func UpdateFilterRule(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type request struct {
+			Action string `json:"action"`
+		}
+
+		if _, ok := requireRole(cfg, w, r, "admin"); !ok {
+			return
+		}
+
+		ruleID, ok := parseRuleID(w, r)
+		if !ok {
+			return
+		}
+
+		var req request
+		if !validate.DecodeJSON(w, r, &req) {
+			return
+		}
+
+		var v validate.Validator
+		validateAction(&v, req.Action)
+		if !v.Valid() {
+			logging.FromContext(r.Context()).Info("invalid filter rule", "errors", v.Errors)
+			v.Write(w, r)
+			return
+		}
+
+		rule, err := cfg.DB.UpdateFilterRule(r.Context(), database.UpdateFilterRuleParams{
+			ID:     ruleID,
+			Action: req.Action,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logging.FromContext(r.Context()).Info("filter rule not found", "rule_id", ruleID)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Filter rule not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("failed to update filter rule", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		logging.FromContext(r.Context()).Info("filter rule updated", "rule_id", rule.ID, "action", rule.Action)
+		writeJSON(w, r, http.StatusOK, newFilterRule(rule))
+	})
+}
+
+// DeleteFilterRule removes a rule. chirps it masked stay masked. admins
+// only.
+func DeleteFilterRule(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := requireRole(cfg, w, r, "admin"); !ok {
+			return
+		}
+
+		ruleID, ok := parseRuleID(w, r)
+		if !ok {
+			return
+		}
+
+		n, err := cfg.DB.DeleteFilterRule(r.Context(), ruleID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to delete filter rule", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		if n == 0 {
+			logging.FromContext(r.Context()).Info("filter rule not found", "rule_id", ruleID)
+			problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Filter rule not found.")
+			return
+		}
+
+		logging.FromContext(r.Context()).Info("filter rule deleted", "rule_id", ruleID)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// ListFilterFlags returns the most recent chirps that matched a flag rule,
+// newest first, at most ?limit= (default 50, max 200). moderators and
+// admins.
+func ListFilterFlags(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type flag struct {
+			ID        uuid.UUID  `json:"id"`
+			CreatedAt time.Time  `json:"created_at"`
+			ChirpID   uuid.UUID  `json:"chirp_id"`
+			ChirpBody string     `json:"chirp_body"`
+			AuthorID  uuid.UUID  `json:"author_id"`
+			RuleID    *uuid.UUID `json:"rule_id"`
+			Matched   string     `json:"matched"`
+		}
+
+		if _, ok := requireRole(cfg, w, r, "moderator", "admin"); !ok {
+			return
+		}
+
+		limit, ok := validate.Limit(w, r, 50, 200)
+		if !ok {
+			return
+		}
+
+		rows, err := cfg.DB.ListFilterFlags(r.Context(), limit)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to list filter flags", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		flags := make([]flag, len(rows))
+		for i, row := range rows {
+			flags[i] = flag{
+				ID:        row.ID,
+				CreatedAt: row.CreatedAt,
+				ChirpID:   row.ChirpID,
+				ChirpBody: row.ChirpBody,
+				AuthorID:  row.ChirpUserID,
+				Matched:   row.Matched,
+			}
+			if row.RuleID.Valid {
+				flags[i].RuleID = &row.RuleID.UUID
+			}
+		}
+		writeJSON(w, r, http.StatusOK, flags)
+	})
+}
+
+func validateAction(v *validate.Validator, action string) {
+	v.Required("action", action)
+	v.Check(filter.Action(action).Valid(), "action", "invalid", "must be mask, reject or flag")
+}
+
+func parseRuleID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	ruleID, err := uuid.Parse(r.PathValue("ruleID"))
+	if err != nil {
+		logging.FromContext(r.Context()).Info("invalid rule ID", "err", err)
+		problem.Error(w, r, http.StatusBadRequest, problem.InvalidID, "Rule ID must be a UUID.")
+		return uuid.Nil, false
+	}
+	return ruleID, true
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logging.FromContext(r.Context()).Error("failed to encode response", "err", err)
+	}
+}
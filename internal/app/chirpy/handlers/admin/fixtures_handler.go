@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/fixtures"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// ListFixtures returns the names of the fixture datasets that can be
+// loaded with POST /admin/fixtures/{name}. dev only.
+func ListFixtures(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !devOnly(cfg, w, r) {
+			return
+		}
+
+		names, err := fixtures.Names()
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to list fixtures", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(names); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response", "err", err)
+		}
+	})
+}
+
+// LoadFixture replaces all users, chirps and refresh tokens with the named
+// fixture dataset. dev only.
+func LoadFixture(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type response struct {
+			Fixture string `json:"fixture"`
+			Users   int    `json:"users"`
+			Chirps  int    `json:"chirps"`
+		}
+
+		if !devOnly(cfg, w, r) {
+			return
+		}
+
+		name := r.PathValue("name")
+		dataset, err := fixtures.Load(r.Context(), cfg.Conn, name, cfg.QueryHooks...)
+		if err != nil {
+			if errors.Is(err, fixtures.ErrNotFound) {
+				logging.FromContext(r.Context()).Info("fixture not found", "name", name)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Fixture not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("failed to load fixture", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		// the metrics counter is part of the starting state as well.
+		cfg.FileserverHits.Store(0)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(w).Encode(response{
+			Fixture: name,
+			Users:   len(dataset.Users),
+			Chirps:  len(dataset.Chirps),
+		}); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response", "err", err)
+		}
+	})
+}
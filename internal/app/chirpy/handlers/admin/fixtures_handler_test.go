@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+)
+
+func TestListFixtures(t *testing.T) {
+	cfg := &chirpy.ApiConfig{Platform: "dev"}
+
+	w := httptest.NewRecorder()
+	ListFixtures(cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/fixtures", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d\n", w.Code)
+	}
+	var names []string
+	if err := json.NewDecoder(w.Body).Decode(&names); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	if !slices.Contains(names, "basic") || !slices.Contains(names, "empty") {
+		t.Errorf("expected basic and empty, got %v\n", names)
+	}
+}
+
+func TestLoadFixtureRejects(t *testing.T) {
+	cases := []struct {
+		platform string
+		name     string
+		status   int
+	}{
+		{"prod", "basic", http.StatusForbidden},
+		{"dev", "does-not-exist", http.StatusNotFound},
+	}
+
+	for _, c := range cases {
+		// neither gets as far as the database, so there's none.
+		cfg := &chirpy.ApiConfig{Platform: c.platform}
+		cfg.FileserverHits.Store(7)
+		mux := http.NewServeMux()
+		mux.Handle("POST /admin/fixtures/{name}", LoadFixture(cfg))
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/fixtures/"+c.name, nil))
+
+		if w.Code != c.status {
+			t.Errorf("%s %s: expected %d, got %d\n", c.platform, c.name, c.status, w.Code)
+		}
+		if hits := cfg.FileserverHits.Load(); hits != 7 {
+			t.Errorf("%s %s: expected hits untouched, got %d\n", c.platform, c.name, hits)
+		}
+	}
+}
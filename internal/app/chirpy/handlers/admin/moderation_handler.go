@@ -0,0 +1,431 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// the roles that can work the moderation queue.
+var moderators = []string{"moderator", "admin"}
+
+// MaxResolutionNoteLength keeps notes to a few sentences.
+const MaxResolutionNoteLength = 1000
+
+// resolutions maps the actions a report can be resolved with to the
+// message the reporter gets.
+var resolutions = map[string]string{
+	"dismiss":        "Thanks for your report. We looked into it and didn't find a violation of our rules.",
+	"hide_chirp":     "Thanks for your report. The chirp you reported has been removed.",
+	"suspend_author": "Thanks for your report. The account you reported has been suspended.",
+}
+
+type report struct {
+	ID             uuid.UUID  `json:"id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	ReporterID     uuid.UUID  `json:"reporter_id"`
+	ReportedUserID uuid.UUID  `json:"reported_user_id"`
+	ReportedHandle string     `json:"reported_handle,omitempty"`
+	ChirpID        *uuid.UUID `json:"chirp_id"`
+	ChirpBody      *string    `json:"chirp_body,omitempty"`
+	Reason         string     `json:"reason"`
+	Details        string     `json:"details"`
+	Status         string     `json:"status"`
+	ClaimedBy      *uuid.UUID `json:"claimed_by"`
+	ClaimedAt      *time.Time `json:"claimed_at"`
+	Resolution     *string    `json:"resolution"`
+	ResolutionNote string     `json:"resolution_note"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+}
+
+func newReport(r database.Report) report {
+	res := report{
+		ID:             r.ID,
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+		ReporterID:     r.ReporterID,
+		ReportedUserID: r.ReportedUserID,
+		Reason:         r.Reason,
+		Details:        r.Details,
+		Status:         r.Status,
+		ResolutionNote: r.ResolutionNote,
+	}
+	if r.ChirpID.Valid {
+		res.ChirpID = &r.ChirpID.UUID
+	}
+	if r.ClaimedBy.Valid {
+		res.ClaimedBy = &r.ClaimedBy.UUID
+	}
+	if r.ClaimedAt.Valid {
+		res.ClaimedAt = &r.ClaimedAt.Time
+	}
+	if r.Resolution.Valid {
+		res.Resolution = &r.Resolution.String
+	}
+	if r.ResolvedAt.Valid {
+		res.ResolvedAt = &r.ResolvedAt.Time
+	}
+	return res
+}
+
+// ListReports is the moderation queue: reports with ?status= (open by
+// default, claimed or resolved), oldest first. moderators and admins.
+func ListReports(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := requireRole(cfg, w, r, moderators...); !ok {
+			return
+		}
+
+		status := r.URL.Query().Get("status")
+		switch status {
+		case "":
+			status = "open"
+		case "open", "claimed", "resolved":
+		default:
+			problem.Fields(w, r, "Invalid query.", problem.FieldError{Field: "status", Code: "invalid", Message: "must be open, claimed or resolved"})
+			return
+		}
+
+		limit, ok := validate.Limit(w, r, 50, 200)
+		if !ok {
+			return
+		}
+
+		rows, err := cfg.DB.ListReportsByStatus(r.Context(), database.ListReportsByStatusParams{
+			Status: status,
+			Limit:  limit,
+		})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to list reports", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		reports := make([]report, len(rows))
+		for i, row := range rows {
+			reports[i] = newReport(database.Report{
+				ID:             row.ID,
+				CreatedAt:      row.CreatedAt,
+				UpdatedAt:      row.UpdatedAt,
+				ReporterID:     row.ReporterID,
+				ReportedUserID: row.ReportedUserID,
+				ChirpID:        row.ChirpID,
+				Reason:         row.Reason,
+				Details:        row.Details,
+				Status:         row.Status,
+				ClaimedBy:      row.ClaimedBy,
+				ClaimedAt:      row.ClaimedAt,
+				Resolution:     row.Resolution,
+				ResolutionNote: row.ResolutionNote,
+				ResolvedAt:     row.ResolvedAt,
+			})
+			reports[i].ReportedHandle = row.ReportedHandle
+			if row.ChirpBody.Valid {
+				reports[i].ChirpBody = &row.ChirpBody.String
+			}
+		}
+		writeJSON(w, r, http.StatusOK, reports)
+	})
+}
+
+// GetReport returns a report and its audit trail. moderators and admins.
+func GetReport(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type auditEntry struct {
+			CreatedAt       time.Time  `json:"created_at"`
+			ModeratorID     *uuid.UUID `json:"moderator_id"`
+			ModeratorHandle *string    `json:"moderator_handle"`
+			Action          string     `json:"action"`
+			Note            string     `json:"note"`
+		}
+		type response struct {
+			report
+			Audit []auditEntry `json:"audit"`
+		}
+
+		if _, ok := requireRole(cfg, w, r, moderators...); !ok {
+			return
+		}
+
+		rep, ok := loadReport(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		rows, err := cfg.DB.GetAuditEntriesByReport(r.Context(), uuid.NullUUID{UUID: rep.ID, Valid: true})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get audit entries", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		res := response{report: newReport(rep), Audit: make([]auditEntry, len(rows))}
+		for i, row := range rows {
+			res.Audit[i] = auditEntry{
+				CreatedAt: row.CreatedAt,
+				Action:    row.Action,
+				Note:      row.Note,
+			}
+			if row.ModeratorID.Valid {
+				res.Audit[i].ModeratorID = &row.ModeratorID.UUID
+			}
+			if row.ModeratorHandle.Valid {
+				res.Audit[i].ModeratorHandle = &row.ModeratorHandle.String
+			}
+		}
+		writeJSON(w, r, http.StatusOK, res)
+	})
+}
+
+// ClaimReport assigns an open report to the moderator, so two moderators
+// don't work on the same one. moderators and admins.
+func ClaimReport(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		moderator, ok := requireRole(cfg, w, r, moderators...)
+		if !ok {
+			return
+		}
+
+		reportID, ok := parseReportID(w, r)
+		if !ok {
+			return
+		}
+
+		var rep database.Report
+		err := cfg.InTx(r.Context(), func(q *database.Queries) error {
+			var err error
+			rep, err = q.ClaimReport(r.Context(), database.ClaimReportParams{
+				ModeratorID: moderator.ID,
+				ID:          reportID,
+			})
+			if err != nil {
+				return err
+			}
+			return audit(r.Context(), q, moderator.ID, rep.ID, "claim", "")
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				reportUnavailable(cfg, w, r, reportID)
+				return
+			}
+			logging.FromContext(r.Context()).Error("failed to claim report", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		logging.FromContext(r.Context()).Info("report claimed", "report_id", rep.ID)
+		writeJSON(w, r, http.StatusOK, newReport(rep))
+	})
+}
+
+// ResolveReport closes a report the moderator has claimed, with one of the
+// actions in resolutions, and lets the reporter know. moderators and
+// admins, but only admins can suspend other moderators or admins.
+func ResolveReport(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type request struct {
+			Action string `json:"action"`
+			Note   string `json:"note"`
+		}
+
+		moderator, ok := requireRole(cfg, w, r, moderators...)
+		if !ok {
+			return
+		}
+
+		rep, ok := loadReport(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		var req request
+		if !validate.DecodeJSON(w, r, &req) {
+			return
+		}
+
+		var v validate.Validator
+		checkResolution(&v, req.Action, req.Note, rep.ChirpID.Valid)
+		if !v.Valid() {
+			logging.FromContext(r.Context()).Info("invalid resolution", "errors", v.Errors)
+			v.Write(w, r)
+			return
+		}
+
+		if req.Action == "suspend_author" {
+			author, err := cfg.DB.GetUserByID(r.Context(), rep.ReportedUserID)
+			if err != nil {
+				logging.FromContext(r.Context()).Error("failed to get reported user", "err", err)
+				problem.InternalError(w, r)
+				return
+			}
+			if author.Role != "user" && moderator.Role != "admin" {
+				logging.FromContext(r.Context()).Warn("moderator tried to suspend staff", "reported_user_id", author.ID)
+				problem.Error(w, r, http.StatusForbidden, problem.Forbidden, "Only admins can suspend moderators and admins.")
+				return
+			}
+		}
+
+		// rep is overwritten by ResolveReport, even when it matches nothing.
+		reportID := rep.ID
+		note := strings.TrimSpace(req.Note)
+		err := cfg.InTx(r.Context(), func(q *database.Queries) error {
+			var err error
+			rep, err = q.ResolveReport(r.Context(), database.ResolveReportParams{
+				Resolution:  req.Action,
+				Note:        note,
+				ID:          reportID,
+				ModeratorID: moderator.ID,
+			})
+			if errors.Is(err, sql.ErrNoRows) {
+				return errReportUnavailable
+			}
+			if err != nil {
+				return err
+			}
+
+			switch req.Action {
+			case "hide_chirp":
+				n, err := q.HideChirp(r.Context(), rep.ChirpID.UUID)
+				if err != nil {
+					return err
+				}
+				if n == 0 {
+					// already hidden is fine, gone isn't.
+					if _, err := q.GetChirp(r.Context(), rep.ChirpID.UUID); err != nil {
+						return err
+					}
+				}
+			case "suspend_author":
+				// like "chirpy user suspend": no new logins and no sessions.
+				if _, err := q.SuspendUser(r.Context(), rep.ReportedUserID); err != nil {
+					return err
+				}
+				if _, err := q.RevokeUserRefreshTokens(r.Context(), rep.ReportedUserID); err != nil {
+					return err
+				}
+			}
+
+			if err := audit(r.Context(), q, moderator.ID, rep.ID, req.Action, note); err != nil {
+				return err
+			}
+
+			return q.CreateNotification(r.Context(), database.CreateNotificationParams{
+				UserID:   rep.ReporterID,
+				Type:     "report_resolved",
+				ReportID: uuid.NullUUID{UUID: rep.ID, Valid: true},
+				Message:  resolutions[req.Action],
+			})
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, errReportUnavailable):
+				reportUnavailable(cfg, w, r, reportID)
+			case errors.Is(err, sql.ErrNoRows):
+				// the chirp or user was deleted since the report was loaded.
+				logging.FromContext(r.Context()).Info("reported chirp or user not found", "report_id", reportID)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "The reported chirp or user no longer exists.")
+			default:
+				logging.FromContext(r.Context()).Error("failed to resolve report", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		logging.FromContext(r.Context()).Info("report resolved", "report_id", rep.ID, "action", req.Action)
+		writeJSON(w, r, http.StatusOK, newReport(rep))
+	})
+}
+
+// checkResolution checks the action and note of a resolution. hide_chirp
+// only makes sense for a report about a chirp that still exists.
+func checkResolution(v *validate.Validator, action, note string, hasChirp bool) {
+	v.Required("action", action)
+	_, known := resolutions[action]
+	v.Check(known, "action", "invalid", "must be dismiss, hide_chirp or suspend_author")
+	v.Check(action != "hide_chirp" || hasChirp, "action", "invalid", "needs a report about a chirp that still exists")
+	v.MaxLength("note", note, MaxResolutionNoteLength)
+}
+
+func audit(ctx context.Context, q *database.Queries, moderatorID, reportID uuid.UUID, action, note string) error {
+	return q.CreateAuditEntry(ctx, database.CreateAuditEntryParams{
+		ModeratorID: uuid.NullUUID{UUID: moderatorID, Valid: true},
+		ReportID:    uuid.NullUUID{UUID: reportID, Valid: true},
+		Action:      action,
+		Note:        note,
+	})
+}
+
+func parseReportID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	reportID, err := uuid.Parse(r.PathValue("reportID"))
+	if err != nil {
+		logging.FromContext(r.Context()).Info("invalid report ID", "err", err)
+		problem.Error(w, r, http.StatusBadRequest, problem.InvalidID, "Report ID must be a UUID.")
+		return uuid.Nil, false
+	}
+	return reportID, true
+}
+
+func loadReport(cfg *chirpy.ApiConfig, w http.ResponseWriter, r *http.Request) (database.Report, bool) {
+	reportID, ok := parseReportID(w, r)
+	if !ok {
+		return database.Report{}, false
+	}
+
+	rep, err := cfg.DB.GetReport(r.Context(), reportID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logging.FromContext(r.Context()).Info("report not found", "report_id", reportID)
+			problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Report not found.")
+		} else {
+			logging.FromContext(r.Context()).Error("database error", "err", err)
+			problem.InternalError(w, r)
+		}
+		return database.Report{}, false
+	}
+	return rep, true
+}
+
+// errReportUnavailable means ResolveReport matched no rows, see
+// reportUnavailable.
+var errReportUnavailable = errors.New("report unavailable")
+
+// reportUnavailable explains why a claim or resolve matched no rows: the
+// report is gone, or in a state that doesn't allow it.
+func reportUnavailable(cfg *chirpy.ApiConfig, w http.ResponseWriter, r *http.Request, reportID uuid.UUID) {
+	rep, err := cfg.DB.GetReport(r.Context(), reportID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Report not found.")
+		} else {
+			logging.FromContext(r.Context()).Error("database error", "err", err)
+			problem.InternalError(w, r)
+		}
+		return
+	}
+
+	logging.FromContext(r.Context()).Info("report unavailable", "report_id", reportID, "status", rep.Status)
+	writeReportUnavailable(w, r, rep.Status)
+}
+
+// writeReportUnavailable writes the 409 for a report in status.
+func writeReportUnavailable(w http.ResponseWriter, r *http.Request, status string) {
+	switch status {
+	case "resolved":
+		problem.Error(w, r, http.StatusConflict, problem.Conflict, "Report is already resolved.")
+	case "claimed":
+		problem.Error(w, r, http.StatusConflict, problem.Conflict, "Report is claimed by another moderator.")
+	default:
+		problem.Error(w, r, http.StatusConflict, problem.Conflict, "Claim the report before resolving it.")
+	}
+}
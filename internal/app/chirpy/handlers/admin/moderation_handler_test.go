@@ -0,0 +1,69 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+)
+
+func TestCheckResolution(t *testing.T) {
+	cases := []struct {
+		name     string
+		action   string
+		note     string
+		hasChirp bool
+		want     string // field with an error, "" if valid
+	}{
+		{"dismiss", "dismiss", "", false, ""},
+		{"hide chirp", "hide_chirp", "spam", true, ""},
+		{"suspend author", "suspend_author", "", false, ""},
+		{"missing action", "", "", true, "action"},
+		{"unknown action", "delete_everything", "", true, "action"},
+		{"hide without chirp", "hide_chirp", "", false, "action"},
+		{"note too long", "dismiss", strings.Repeat("a", MaxResolutionNoteLength+1), true, "note"},
+	}
+
+	for _, c := range cases {
+		var v validate.Validator
+		checkResolution(&v, c.action, c.note, c.hasChirp)
+		if c.want == "" {
+			if !v.Valid() {
+				t.Errorf("%s: expected valid, got %+v\n", c.name, v.Errors)
+			}
+			continue
+		}
+		if len(v.Errors) != 1 || v.Errors[0].Field != c.want {
+			t.Errorf("%s: expected one error on %s, got %+v\n", c.name, c.want, v.Errors)
+		}
+	}
+}
+
+func TestWriteReportUnavailable(t *testing.T) {
+	cases := map[string]string{
+		"resolved": "Report is already resolved.",
+		"claimed":  "Report is claimed by another moderator.",
+		"open":     "Claim the report before resolving it.",
+	}
+
+	for status, detail := range cases {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/admin/moderation/reports/x/resolve", nil)
+		writeReportUnavailable(w, r, status)
+
+		if w.Code != http.StatusConflict {
+			t.Errorf("%s: expected 409, got %d\n", status, w.Code)
+		}
+		var p problem.Problem
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("%s: invalid problem body: %v\n", status, err)
+		}
+		if p.Code != problem.Conflict || p.Detail != detail {
+			t.Errorf("%s: unexpected problem %+v\n", status, p)
+		}
+	}
+}
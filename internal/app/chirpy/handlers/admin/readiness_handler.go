@@ -1,12 +1,21 @@
 package admin
 
 import (
-	"log"
+	"context"
+	"encoding/json"
 	"net/http"
+	"time"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/database/migrate"
+	"github.com/johndosdos/chirpy/internal/logging"
 )
 
+// Check registers the liveness endpoints. they only tell whether the
+// process is up and serving HTTP; use Ready to know whether it can
+// actually handle traffic. /api/healthz is kept for existing clients.
 func Check(mux *http.ServeMux) {
-	mux.HandleFunc("GET /api/healthz", func(w http.ResponseWriter, r *http.Request) {
+	live := func(w http.ResponseWriter, r *http.Request) {
 		/*
 			http.ResponseWriter handles the response that our server sends back
 			to the client.
@@ -23,7 +32,112 @@ func Check(mux *http.ServeMux) {
 		*/
 		_, err := w.Write([]byte("OK"))
 		if err != nil {
-			log.Printf("failed to write healthz response: %v", err)
+			logging.FromContext(r.Context()).Error("failed to write healthz response", "err", err)
+		}
+	}
+
+	mux.HandleFunc("GET /api/healthz", live)
+	mux.HandleFunc("GET /api/livez", live)
+}
+
+type componentStatus struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms,omitempty"`
+	Current   *int64  `json:"current_version,omitempty"`
+	Expected  *int64  `json:"expected_version,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+
+	readyCheckTimeout = 2 * time.Second
+)
+
+// Ready reports whether the server can take traffic: it is neither starting
+// up nor draining, the database answers within readyCheckTimeout and the
+// schema is migrated to the newest embedded migration. it responds 200 when every
+// component is ok and 503 otherwise, with per-component details as JSON.
+func Ready(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type response struct {
+			Status     string                     `json:"status"`
+			Components map[string]componentStatus `json:"components"`
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), readyCheckTimeout)
+		defer cancel()
+
+		components := map[string]componentStatus{
+			"server":     checkServer(cfg),
+			"database":   checkDatabase(ctx, cfg),
+			"migrations": checkMigrations(ctx, cfg),
+		}
+
+		res := response{Status: statusOK, Components: components}
+		for name, component := range components {
+			if component.Status != statusOK {
+				res.Status = statusUnavailable
+				logging.FromContext(r.Context()).Warn("readiness check failed", "component", name, "error", component.Error)
+			}
+		}
+
+		// readiness must never be cached by a proxy.
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Content-Type", "application/json")
+		if res.Status == statusOK {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response", "err", err)
 		}
 	})
 }
+
+func checkServer(cfg *chirpy.ApiConfig) componentStatus {
+	if !cfg.Ready.Load() {
+		return componentStatus{Status: statusUnavailable, Error: "starting up or shutting down"}
+	}
+
+	return componentStatus{Status: statusOK}
+}
+
+func checkDatabase(ctx context.Context, cfg *chirpy.ApiConfig) componentStatus {
+	start := time.Now()
+	err := cfg.Conn.PingContext(ctx)
+	latency := float64(time.Since(start).Microseconds()) / 1000
+
+	// the driver error may contain hosts and user names, which don't
+	// belong in a public response, so it only goes to the logs.
+	if err != nil {
+		logging.FromContext(ctx).Warn("database ping failed", "err", err)
+		return componentStatus{Status: statusUnavailable, LatencyMs: latency, Error: "database unreachable"}
+	}
+
+	return componentStatus{Status: statusOK, LatencyMs: latency}
+}
+
+func checkMigrations(ctx context.Context, cfg *chirpy.ApiConfig) componentStatus {
+	expected := migrate.LatestVersion()
+
+	// goose keeps one row per applied migration and removes it when the
+	// migration is rolled back.
+	var current int64
+	err := cfg.Conn.QueryRowContext(ctx,
+		"SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied",
+	).Scan(&current)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to read schema version", "err", err)
+		return componentStatus{Status: statusUnavailable, Expected: &expected, Error: "failed to read schema version"}
+	}
+
+	if current != expected {
+		return componentStatus{Status: statusUnavailable, Current: &current, Expected: &expected, Error: "schema version mismatch"}
+	}
+
+	return componentStatus{Status: statusOK, Current: &current, Expected: &expected}
+}
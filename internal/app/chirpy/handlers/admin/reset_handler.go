@@ -0,0 +1,94 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// resetters maps the {target} path value of POST /admin/reset/{target} to
+// the function that resets it. ResetAll runs them in resetOrder.
+var resetters = map[string]func(ctx context.Context, cfg *chirpy.ApiConfig) error{
+	"metrics": func(ctx context.Context, cfg *chirpy.ApiConfig) error {
+		cfg.FileserverHits.Store(0)
+		return nil
+	},
+	"tokens": func(ctx context.Context, cfg *chirpy.ApiConfig) error {
+		return cfg.DB.DeleteRefreshTokens(ctx)
+	},
+	"chirps": func(ctx context.Context, cfg *chirpy.ApiConfig) error {
+		return cfg.DB.DeleteChirps(ctx)
+	},
+	"users": func(ctx context.Context, cfg *chirpy.ApiConfig) error {
+		// chirps and refresh tokens are removed as well through
+		// ON DELETE CASCADE.
+		return cfg.DB.DeleteUsers(ctx)
+	},
+}
+
+var resetOrder = []string{"metrics", "tokens", "chirps", "users"}
+
+// ResetAll resets metrics and wipes every table. dev only.
+func ResetAll(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !devOnly(cfg, w, r) {
+			return
+		}
+
+		for _, target := range resetOrder {
+			if err := resetters[target](r.Context(), cfg); err != nil {
+				logging.FromContext(r.Context()).Error("failed to reset target", "target", target, "err", err)
+				problem.InternalError(w, r)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		for _, target := range resetOrder {
+			fmt.Fprintf(w, "Reset %s\n", target)
+		}
+	})
+}
+
+// Reset resets a single target: metrics, chirps, users or tokens. dev only.
+func Reset(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !devOnly(cfg, w, r) {
+			return
+		}
+
+		target := r.PathValue("target")
+		reset, ok := resetters[target]
+		if !ok {
+			logging.FromContext(r.Context()).Info("unknown reset target", "target", target)
+			problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Unknown reset target.")
+			return
+		}
+
+		if err := reset(r.Context(), cfg); err != nil {
+			logging.FromContext(r.Context()).Error("failed to reset target", "target", target, "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "Reset %s\n", target)
+	})
+}
+
+// devOnly writes a 403 and returns false if the server is not running on
+// the dev platform.
+func devOnly(cfg *chirpy.ApiConfig, w http.ResponseWriter, r *http.Request) bool {
+	if cfg.Platform != "dev" {
+		problem.Error(w, r, http.StatusForbidden, problem.Forbidden, "Only available when the platform is dev.")
+		return false
+	}
+
+	return true
+}
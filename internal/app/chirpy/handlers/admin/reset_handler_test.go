@@ -0,0 +1,140 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/database"
+)
+
+// fakeDB records the :exec queries it's given and fails the one named
+// failOn. nothing here reads rows.
+type fakeDB struct {
+	failOn string
+	execs  []string
+}
+
+func (db *fakeDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	name := database.QueryName(query)
+	db.execs = append(db.execs, name)
+	if name == db.failOn {
+		return nil, errors.New("connection reset")
+	}
+	return driverResult{}, nil
+}
+
+func (db *fakeDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, errors.New("unexpected prepare")
+}
+
+func (db *fakeDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errors.New("unexpected query")
+}
+
+func (db *fakeDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	panic("unexpected query " + database.QueryName(query))
+}
+
+type driverResult struct{}
+
+func (driverResult) LastInsertId() (int64, error) { return 0, nil }
+func (driverResult) RowsAffected() (int64, error) { return 0, nil }
+
+// newResetMux serves the reset handlers on a dev or prod config backed by
+// db.
+func newResetMux(platform string, db *fakeDB) (*http.ServeMux, *chirpy.ApiConfig) {
+	cfg := &chirpy.ApiConfig{Platform: platform, DB: database.New(db)}
+	mux := http.NewServeMux()
+	mux.Handle("POST /admin/reset", ResetAll(cfg))
+	mux.Handle("POST /admin/reset/{target}", Reset(cfg))
+	return mux, cfg
+}
+
+func TestResetAll(t *testing.T) {
+	db := &fakeDB{}
+	mux, cfg := newResetMux("dev", db)
+	cfg.FileserverHits.Store(7)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/reset", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d\n", w.Code)
+	}
+	if hits := cfg.FileserverHits.Load(); hits != 0 {
+		t.Errorf("expected hits reset, got %d\n", hits)
+	}
+	want := []string{"DeleteRefreshTokens", "DeleteChirps", "DeleteUsers"}
+	if !slices.Equal(db.execs, want) {
+		t.Errorf("expected %v, got %v\n", want, db.execs)
+	}
+	if body := w.Body.String(); body != "Reset metrics\nReset tokens\nReset chirps\nReset users\n" {
+		t.Errorf("unexpected body %q\n", body)
+	}
+}
+
+func TestResetAllStopsOnError(t *testing.T) {
+	db := &fakeDB{failOn: "DeleteChirps"}
+	mux, _ := newResetMux("dev", db)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/reset", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d\n", w.Code)
+	}
+	if want := []string{"DeleteRefreshTokens", "DeleteChirps"}; !slices.Equal(db.execs, want) {
+		t.Errorf("expected %v, got %v\n", want, db.execs)
+	}
+}
+
+func TestReset(t *testing.T) {
+	cases := []struct {
+		platform string
+		target   string
+		status   int
+		execs    []string
+	}{
+		{"dev", "tokens", http.StatusOK, []string{"DeleteRefreshTokens"}},
+		{"dev", "users", http.StatusOK, []string{"DeleteUsers"}},
+		{"dev", "metrics", http.StatusOK, nil},
+		{"dev", "everything", http.StatusNotFound, nil},
+		{"prod", "users", http.StatusForbidden, nil},
+	}
+
+	for _, c := range cases {
+		db := &fakeDB{}
+		mux, _ := newResetMux(c.platform, db)
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/reset/"+c.target, nil))
+
+		if w.Code != c.status {
+			t.Errorf("%s %s: expected %d, got %d\n", c.platform, c.target, c.status, w.Code)
+		}
+		if !slices.Equal(db.execs, c.execs) {
+			t.Errorf("%s %s: expected %v, got %v\n", c.platform, c.target, c.execs, db.execs)
+		}
+	}
+}
+
+func TestResetAllProd(t *testing.T) {
+	db := &fakeDB{}
+	mux, _ := newResetMux("prod", db)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/reset", nil))
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d\n", w.Code)
+	}
+	if len(db.execs) != 0 {
+		t.Errorf("expected nothing reset, got %v\n", db.execs)
+	}
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+)
+
+// authenticate is ApiConfig.Authenticate for handlers that only need the
+// user's ID.
+func authenticate(cfg *chirpy.ApiConfig, w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	user, ok := cfg.Authenticate(w, r)
+	return user.ID, ok
+}
+
+// viewer is authenticate for endpoints that also work anonymously: without
+// an Authorization header it returns a NULL user. a header with a bad
+// token is still a 401, rather than quietly showing what anonymous users
+// see.
+func viewer(cfg *chirpy.ApiConfig, w http.ResponseWriter, r *http.Request) (uuid.NullUUID, bool) {
+	if r.Header.Get("Authorization") == "" {
+		return uuid.NullUUID{}, true
+	}
+
+	userID, ok := authenticate(cfg, w, r)
+	return uuid.NullUUID{UUID: userID, Valid: ok}, ok
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// Unblock removes a block. unblocking someone who isn't blocked is fine.
+func Unblock(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		target, ok := relationTarget(cfg, w, r, userID)
+		if !ok {
+			return
+		}
+
+		_, err := cfg.DB.UnblockUser(r.Context(), database.UnblockUserParams{
+			BlockerID: userID,
+			BlockedID: target.ID,
+		})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to unblock user", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		logging.FromContext(r.Context()).Info("user unblocked", "blocked_id", target.ID)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// Unmute removes a mute. unmuting someone who isn't muted is fine.
+func Unmute(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		target, ok := relationTarget(cfg, w, r, userID)
+		if !ok {
+			return
+		}
+
+		_, err := cfg.DB.UnmuteUser(r.Context(), database.UnmuteUserParams{
+			MuterID: userID,
+			MutedID: target.ID,
+		})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to unmute user", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		logging.FromContext(r.Context()).Info("user unmuted", "muted_id", target.ID)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
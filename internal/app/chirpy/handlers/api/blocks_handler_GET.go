@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// relatedUser is an entry of the block and mute lists: the user, as in
+// chirps, and when they were blocked or muted.
+type relatedUser struct {
+	author
+	Since time.Time `json:"since"`
+}
+
+// GetBlocks lists the users you blocked, most recent first.
+func GetBlocks(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		rows, err := cfg.DB.GetBlockedUsers(r.Context(), userID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get blocked users", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		users := make([]relatedUser, len(rows))
+		for i, row := range rows {
+			users[i] = relatedUser{
+				author: author{ID: row.ID, Handle: row.Handle, DisplayName: row.DisplayName, AvatarURL: row.AvatarUrl},
+				Since:  row.CreatedAt,
+			}
+		}
+		writeRelatedUsers(w, r, users)
+	})
+}
+
+// GetMutes lists the users you muted, most recent first.
+func GetMutes(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		rows, err := cfg.DB.GetMutedUsers(r.Context(), userID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get muted users", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		users := make([]relatedUser, len(rows))
+		for i, row := range rows {
+			users[i] = relatedUser{
+				author: author{ID: row.ID, Handle: row.Handle, DisplayName: row.DisplayName, AvatarURL: row.AvatarUrl},
+				Since:  row.CreatedAt,
+			}
+		}
+		writeRelatedUsers(w, r, users)
+	})
+}
+
+func writeRelatedUsers(w http.ResponseWriter, r *http.Request, users []relatedUser) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(users); err != nil {
+		logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+	}
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// Block blocks the user with the given handle: neither user sees the
+// other's profile or chirps from then on. blocking someone twice is fine.
+func Block(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		target, ok := relationTarget(cfg, w, r, userID)
+		if !ok {
+			return
+		}
+
+		err := cfg.DB.BlockUser(r.Context(), database.BlockUserParams{
+			BlockerID: userID,
+			BlockedID: target.ID,
+		})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to block user", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		logging.FromContext(r.Context()).Info("user blocked", "blocked_id", target.ID)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// Mute hides the chirps of the user with the given handle from the muter's
+// GET /api/chirps. the muted user isn't told and can still see the muter.
+// muting someone twice is fine.
+func Mute(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		target, ok := relationTarget(cfg, w, r, userID)
+		if !ok {
+			return
+		}
+
+		err := cfg.DB.MuteUser(r.Context(), database.MuteUserParams{
+			MuterID: userID,
+			MutedID: target.ID,
+		})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to mute user", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		logging.FromContext(r.Context()).Info("user muted", "muted_id", target.ID)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// relationTarget looks up the {handle} of a block or mute request. blocks
+// are ignored, so users who blocked you can be blocked back and blocked
+// users can be unblocked.
+func relationTarget(cfg *chirpy.ApiConfig, w http.ResponseWriter, r *http.Request, userID uuid.UUID) (database.User, bool) {
+	row, err := cfg.DB.GetProfileByHandle(r.Context(), database.GetProfileByHandleParams{
+		Handle: r.PathValue("handle"),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logging.FromContext(r.Context()).Info("profile not found", "handle", r.PathValue("handle"))
+			problem.Error(w, r, http.StatusNotFound, problem.NotFound, "User not found.")
+		} else {
+			logging.FromContext(r.Context()).Error("database error", "err", err)
+			problem.InternalError(w, r)
+		}
+		return database.User{}, false
+	}
+
+	if row.User.ID == userID {
+		logging.FromContext(r.Context()).Info("relation with self")
+		problem.Error(w, r, http.StatusBadRequest, problem.ValidationFailed, "You can't block or mute yourself.")
+		return database.User{}, false
+	}
+
+	return row.User, true
+}
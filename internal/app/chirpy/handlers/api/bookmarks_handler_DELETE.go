@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// UnbookmarkChirp removes a chirp from the user's bookmarks. removing one
+// that isn't bookmarked is fine.
+func UnbookmarkChirp(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+		if err != nil {
+			logging.FromContext(r.Context()).Info("invalid chirp ID", "err", err)
+			problem.Error(w, r, http.StatusBadRequest, problem.InvalidID, "Chirp ID must be a UUID.")
+			return
+		}
+
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		_, err = cfg.DB.UnbookmarkChirp(r.Context(), database.UnbookmarkChirpParams{
+			UserID:  userID,
+			ChirpID: chirpID,
+		})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to remove bookmark", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// DeleteBookmarkFolder deletes one of the user's folders. its bookmarks
+// are kept, outside any folder.
+func DeleteBookmarkFolder(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		folderID, err := uuid.Parse(r.PathValue("folderID"))
+		if err != nil {
+			logging.FromContext(r.Context()).Info("invalid folder ID", "err", err)
+			problem.Error(w, r, http.StatusBadRequest, problem.InvalidID, "Folder ID must be a UUID.")
+			return
+		}
+
+		n, err := cfg.DB.DeleteBookmarkFolder(r.Context(), database.DeleteBookmarkFolderParams{
+			ID:     folderID,
+			UserID: userID,
+		})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to delete bookmark folder", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		if n == 0 {
+			logging.FromContext(r.Context()).Info("bookmark folder not found", "folder_id", folderID)
+			problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Folder not found.")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// GetBookmarks returns the user's bookmarked chirps, most recently
+// bookmarked first. ?folder_id= limits them to one folder, ?limit= defaults
+// to 50, at most 200. for the next page, pass the bookmarked_at of the last
+// one as ?before=.
+func GetBookmarks(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type bookmark struct {
+			BookmarkedAt time.Time     `json:"bookmarked_at"`
+			FolderID     *uuid.UUID    `json:"folder_id"`
+			Chirp        chirpResponse `json:"chirp"`
+		}
+
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		query := r.URL.Query()
+		params := database.GetBookmarksParams{UserID: userID}
+		if s := query.Get("folder_id"); s != "" {
+			id, err := uuid.Parse(s)
+			if err != nil {
+				problem.Fields(w, r, "Invalid query.", problem.FieldError{Field: "folder_id", Code: "invalid", Message: "must be a UUID"})
+				return
+			}
+			params.FolderID = uuid.NullUUID{UUID: id, Valid: true}
+		}
+		if s := query.Get("before"); s != "" {
+			before, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				problem.Fields(w, r, "Invalid query.", problem.FieldError{Field: "before", Code: "invalid", Message: "must be an RFC 3339 time"})
+				return
+			}
+			params.Before = sql.NullTime{Time: before, Valid: true}
+		}
+		params.RowLimit, ok = validate.Limit(w, r, 50, 200)
+		if !ok {
+			return
+		}
+
+		rows, err := cfg.DB.GetBookmarks(r.Context(), params)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get bookmarks", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		chirps := make([]chirpResponse, len(rows))
+		for i, row := range rows {
+			chirps[i] = newChirpResponse(database.GetChirpWithAuthorRow{
+				Chirp:             row.Chirp,
+				AuthorHandle:      row.AuthorHandle,
+				AuthorDisplayName: row.AuthorDisplayName,
+				AuthorAvatarUrl:   row.AuthorAvatarUrl,
+			})
+		}
+		if err := withMedia(r.Context(), cfg.DB, chirps); err != nil {
+			logging.FromContext(r.Context()).Error("failed to get chirp media", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		if err := withQuotes(r.Context(), cfg.DB, uuid.NullUUID{UUID: userID, Valid: true}, chirps); err != nil {
+			logging.FromContext(r.Context()).Error("failed to get quotes", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		bookmarks := make([]bookmark, len(rows))
+		for i, row := range rows {
+			bookmarks[i] = bookmark{BookmarkedAt: row.BookmarkedAt, Chirp: chirps[i]}
+			if row.FolderID.Valid {
+				bookmarks[i].FolderID = &row.FolderID.UUID
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(bookmarks); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+		}
+	})
+}
+
+// GetBookmarkFolders lists the user's bookmark folders by name.
+func GetBookmarkFolders(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		rows, err := cfg.DB.GetBookmarkFolders(r.Context(), userID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get bookmark folders", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		folders := make([]bookmarkFolderResponse, len(rows))
+		for i, row := range rows {
+			folders[i] = newBookmarkFolderResponse(row)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(folders); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+const (
+	// MaxBookmarkFolders is how many folders a user can have.
+	MaxBookmarkFolders = 100
+
+	MaxBookmarkFolderNameLength = 50
+)
+
+var errTooManyBookmarkFolders = errors.New("too many bookmark folders")
+
+type bookmarkFolderResponse struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Name      string    `json:"name"`
+}
+
+func newBookmarkFolderResponse(f database.BookmarkFolder) bookmarkFolderResponse {
+	return bookmarkFolderResponse{
+		ID:        f.ID,
+		CreatedAt: f.CreatedAt,
+		Name:      f.Name,
+	}
+}
+
+// BookmarkChirp saves a chirp the user can see to their bookmarks, in
+// folder_id if given. the body is optional. bookmarking a chirp again moves
+// it to the given folder, or out of any folder.
+func BookmarkChirp(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type request struct {
+			FolderID *uuid.UUID `json:"folder_id"`
+		}
+
+		chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+		if err != nil {
+			logging.FromContext(r.Context()).Info("invalid chirp ID", "err", err)
+			problem.Error(w, r, http.StatusBadRequest, problem.InvalidID, "Chirp ID must be a UUID.")
+			return
+		}
+
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		var req request
+		if r.ContentLength != 0 && !validate.DecodeJSON(w, r, &req) {
+			return
+		}
+
+		_, err = cfg.DB.GetChirpWithAuthor(r.Context(), database.GetChirpWithAuthorParams{
+			ID:       chirpID,
+			ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logging.FromContext(r.Context()).Info("chirp not found", "err", err)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Chirp not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("database error", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		var folderID uuid.NullUUID
+		if req.FolderID != nil {
+			_, err := cfg.DB.GetBookmarkFolder(r.Context(), database.GetBookmarkFolderParams{
+				ID:     *req.FolderID,
+				UserID: userID,
+			})
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					logging.FromContext(r.Context()).Info("bookmark folder not found", "folder_id", *req.FolderID)
+					problem.Fields(w, r, "The request has invalid fields.", problem.FieldError{
+						Field:   "folder_id",
+						Code:    "not_found",
+						Message: "must be one of your bookmark folders",
+					})
+				} else {
+					logging.FromContext(r.Context()).Error("database error", "err", err)
+					problem.InternalError(w, r)
+				}
+				return
+			}
+			folderID = uuid.NullUUID{UUID: *req.FolderID, Valid: true}
+		}
+
+		err = cfg.DB.BookmarkChirp(r.Context(), database.BookmarkChirpParams{
+			UserID:   userID,
+			ChirpID:  chirpID,
+			FolderID: folderID,
+		})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to bookmark chirp", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// CreateBookmarkFolder adds a named folder to put bookmarks in. names are
+// unique per user, ignoring case.
+func CreateBookmarkFolder(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type request struct {
+			Name string `json:"name"`
+		}
+
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		var req request
+		if !validate.DecodeJSON(w, r, &req) {
+			return
+		}
+		req.Name = strings.TrimSpace(req.Name)
+
+		var v validate.Validator
+		v.Required("name", req.Name)
+		v.MaxLength("name", req.Name, MaxBookmarkFolderNameLength)
+		if !v.Valid() {
+			logging.FromContext(r.Context()).Info("invalid bookmark folder", "errors", v.Errors)
+			v.Write(w, r)
+			return
+		}
+
+		var folder database.BookmarkFolder
+		err := cfg.InTx(r.Context(), func(q *database.Queries) error {
+			count, err := q.CountBookmarkFolders(r.Context(), userID)
+			if err != nil {
+				return err
+			}
+			if count >= MaxBookmarkFolders {
+				return errTooManyBookmarkFolders
+			}
+
+			folder, err = q.CreateBookmarkFolder(r.Context(), database.CreateBookmarkFolderParams{
+				UserID: userID,
+				Name:   req.Name,
+			})
+			return err
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, errTooManyBookmarkFolders):
+				logging.FromContext(r.Context()).Info("too many bookmark folders")
+				problem.Error(w, r, http.StatusConflict, problem.Conflict, fmt.Sprintf("You can't have more than %d bookmark folders.", MaxBookmarkFolders))
+			case database.IsUniqueViolation(err, database.BookmarkFoldersUserNameKey):
+				logging.FromContext(r.Context()).Info("bookmark folder already exists")
+				problem.Error(w, r, http.StatusConflict, problem.Conflict, "You already have a folder with this name.")
+			default:
+				logging.FromContext(r.Context()).Error("failed to create bookmark folder", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(newBookmarkFolderResponse(folder)); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+		}
+	})
+}
@@ -3,12 +3,12 @@ package api
 import (
 	"database/sql"
 	"errors"
-	"log"
 	"net/http"
 
 	"github.com/google/uuid"
 	"github.com/johndosdos/chirpy/internal/app/chirpy"
-	"github.com/johndosdos/chirpy/internal/auth"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/logging"
 )
 
 func DeleteChirp(cfg *chirpy.ApiConfig) http.Handler {
@@ -17,23 +17,14 @@ func DeleteChirp(cfg *chirpy.ApiConfig) http.Handler {
 		// from URL.
 		chirpID, err := uuid.Parse(r.PathValue("chirpID"))
 		if err != nil {
-			log.Println("invalid chirp ID: ", err)
-			http.Error(w, "Bad request: invalid chirp ID format", http.StatusBadRequest)
+			logging.FromContext(r.Context()).Info("invalid chirp ID", "err", err)
+			problem.Error(w, r, http.StatusBadRequest, problem.InvalidID, "Chirp ID must be a UUID.")
 			return
 		}
 
-		// authenticate access token and then validate it.
-		tokenString, err := auth.GetBearerToken(r.Header)
-		if err != nil {
-			log.Println("invalid authorization header: ", err)
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
-
-		userID, err := auth.ValidateJWT(tokenString, cfg.Secret)
-		if err != nil {
-			log.Println("invalid access token: ", err)
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		// authenticate the user.
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
 			return
 		}
 
@@ -41,27 +32,28 @@ func DeleteChirp(cfg *chirpy.ApiConfig) http.Handler {
 		chirp, err := cfg.DB.GetChirp(r.Context(), chirpID)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
-				log.Println("chirp not found: ", err)
-				http.Error(w, "Not found: chirp not found", http.StatusNotFound)
+				logging.FromContext(r.Context()).Info("chirp not found", "err", err)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Chirp not found.")
 			} else {
-				log.Println("database error: ", err)
-				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				logging.FromContext(r.Context()).Error("database error", "err", err)
+				problem.InternalError(w, r)
 			}
 			return
 		}
 
 		if userID != chirp.UserID {
-			log.Println("chirp deletion not allowed.")
-			http.Error(w, "Forbidden", http.StatusForbidden)
+			logging.FromContext(r.Context()).Warn("chirp deletion not allowed")
+			problem.Error(w, r, http.StatusForbidden, problem.Forbidden, "You can only delete your own chirps.")
 			return
 		}
 
 		// if no errors were present, authorize current user to delete
-		// chirp by their id.
+		// chirp by their id. its bookmarks, revisions and media links are
+		// deleted with it by the database.
 		err = cfg.DB.DeleteChirp(r.Context(), chirpID)
 		if err != nil {
-			log.Println("failed to delete chirp: ", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("failed to delete chirp", "err", err)
+			problem.InternalError(w, r)
 			return
 		}
 
@@ -1,14 +1,17 @@
 package api
 
 import (
+	"database/sql"
 	"encoding/json"
-	"log"
+	"errors"
 	"net/http"
 	"sort"
 
 	"github.com/google/uuid"
 	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
 	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
 )
 
 func GetChirp(cfg *chirpy.ApiConfig) http.Handler {
@@ -18,21 +21,42 @@ func GetChirp(cfg *chirpy.ApiConfig) http.Handler {
 		// using http.Request.PathValue.
 		chirpID, err := uuid.Parse(r.PathValue("chirpID"))
 		if err != nil {
-			log.Println("invalid chirp ID: ", err)
-			http.Error(w, "Bad request: invalid chirp ID format", http.StatusBadRequest)
+			logging.FromContext(r.Context()).Info("invalid chirp ID", "err", err)
+			problem.Error(w, r, http.StatusBadRequest, problem.InvalidID, "Chirp ID must be a UUID.")
 			return
 		}
 
-		// retrieve user chirp from database.
-		//
-		// because 'emit_json_tags' option in sqlc is set to true, we don't need
-		// explicity create a response struct with json tags. check chirps_handler_POST.go
-		// for examples. idk if this is a good practice.
-		chirp, err := cfg.DB.GetChirp(r.Context(), chirpID)
+		viewerID, ok := viewer(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		// retrieve user chirp, and its author, from database. chirps hidden
+		// from the viewer by a block are not found.
+		row, err := cfg.DB.GetChirpWithAuthor(r.Context(), database.GetChirpWithAuthorParams{
+			ID:       chirpID,
+			ViewerID: viewerID,
+		})
 		if err != nil {
-			log.Println(err)
-			w.WriteHeader(http.StatusNotFound)
-			http.Error(w, "User chirp data not found", http.StatusNotFound)
+			if errors.Is(err, sql.ErrNoRows) {
+				logging.FromContext(r.Context()).Info("chirp not found", "err", err)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Chirp not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("database error", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		chirp := []chirpResponse{newChirpResponse(row)}
+		if err := withMedia(r.Context(), cfg.DB, chirp); err != nil {
+			logging.FromContext(r.Context()).Error("failed to get chirp media", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		if err := withQuotes(r.Context(), cfg.DB, viewerID, chirp); err != nil {
+			logging.FromContext(r.Context()).Error("failed to get quotes", "err", err)
+			problem.InternalError(w, r)
 			return
 		}
 
@@ -42,10 +66,9 @@ func GetChirp(cfg *chirpy.ApiConfig) http.Handler {
 		// successful call to w.
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		err = json.NewEncoder(w).Encode(chirp)
+		err = json.NewEncoder(w).Encode(chirp[0])
 		if err != nil {
-			log.Println("Failed to encode response json: ", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
 			return
 		}
 	})
@@ -53,11 +76,27 @@ func GetChirp(cfg *chirpy.ApiConfig) http.Handler {
 
 func GetChirps(cfg *chirpy.ApiConfig) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// get data from db
-		chirps, err := cfg.DB.GetChirps(r.Context())
+		viewerID, ok := viewer(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		// extract optional query parameter 'author_id' from URL
+		authorID := r.URL.Query().Get("author_id")
+
+		// get data from db. chirps of users the viewer muted are left out,
+		// unless the viewer asked for that author on purpose.
+		muterID := viewerID
+		if authorID != "" {
+			muterID = uuid.NullUUID{}
+		}
+		rows, err := cfg.DB.GetChirpsWithAuthors(r.Context(), database.GetChirpsWithAuthorsParams{
+			ViewerID: viewerID,
+			MuterID:  muterID,
+		})
 		if err != nil {
-			log.Println(err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("failed to get chirps", "err", err)
+			problem.InternalError(w, r)
 			return
 		}
 
@@ -68,47 +107,49 @@ func GetChirps(cfg *chirpy.ApiConfig) http.Handler {
 		sortOrder := r.URL.Query().Get("sort")
 
 		if sortOrder != "" && sortOrder == "desc" {
-			sort.Slice(chirps, func(i, j int) bool {
-				return chirps[i].CreatedAt.After(chirps[j].CreatedAt)
+			sort.Slice(rows, func(i, j int) bool {
+				return rows[i].Chirp.CreatedAt.After(rows[j].Chirp.CreatedAt)
 			})
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-
-		// extract optional query parameter 'author_id' from URL
-		authorID := r.URL.Query().Get("author_id")
-
-		// return the array of chirps if author_id is nil
-		if authorID == "" {
-			// encode []chirps directly to w
-			encoder := json.NewEncoder(w)
-			err = encoder.Encode(chirps)
-			if err != nil {
-				log.Println("Failed to encode response json: ", err)
-				http.Error(w, "Internal server error", http.StatusInternalServerError)
-			}
+		// the viewer's muted words apply everywhere, author_id or not. their
+		// own chirps are never hidden from them.
+		muter, err := muterFor(r.Context(), cfg, viewerID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get muted words", "err", err)
+			problem.InternalError(w, r)
 			return
 		}
 
-		// return single chirp if author_id is not nil
-		//
-		// in case of multiple chirps with the same author_id, we
-		// need to store them in a slice. This prevents multiple
-		// writes to w, which is efficient
-		var filteredChirps []database.Chirp
-
-		for _, chirp := range chirps {
-			if chirp.UserID != uuid.Nil && chirp.UserID.String() == authorID {
-				filteredChirps = append(filteredChirps, chirp)
+		// build a single slice so we only write to w once. when author_id is
+		// set, only that author's chirps make it in.
+		chirps := []chirpResponse{}
+		for _, row := range rows {
+			if authorID != "" && row.Chirp.UserID.String() != authorID {
+				continue
 			}
+			if row.Chirp.UserID != viewerID.UUID && muter.Mutes(row.Chirp.Body) {
+				continue
+			}
+			chirps = append(chirps, newChirpResponse(database.GetChirpWithAuthorRow(row)))
+		}
+		if err := withMedia(r.Context(), cfg.DB, chirps); err != nil {
+			logging.FromContext(r.Context()).Error("failed to get chirp media", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		if err := withQuotes(r.Context(), cfg.DB, viewerID, chirps); err != nil {
+			logging.FromContext(r.Context()).Error("failed to get quotes", "err", err)
+			problem.InternalError(w, r)
+			return
 		}
 
-		encoder := json.NewEncoder(w)
-		err = encoder.Encode(filteredChirps)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(chirps)
 		if err != nil {
-			log.Println("Failed to encode response json: ", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
 			return
 		}
 	})
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// EditChirp changes the body of a chirp, for its author and only within
+// the edit window. the body goes through the same checks as a new chirp,
+// and the previous version is kept for GET /api/chirps/{chirpID}/history.
+func EditChirp(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type request struct {
+			Body string `json:"body"`
+		}
+
+		chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+		if err != nil {
+			logging.FromContext(r.Context()).Info("invalid chirp ID", "err", err)
+			problem.Error(w, r, http.StatusBadRequest, problem.InvalidID, "Chirp ID must be a UUID.")
+			return
+		}
+
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		var req request
+		if !validate.DecodeJSON(w, r, &req) {
+			return
+		}
+
+		chirp, err := cfg.DB.GetChirp(r.Context(), chirpID)
+		if err != nil || chirp.HiddenAt.Valid {
+			if err == nil || errors.Is(err, sql.ErrNoRows) {
+				logging.FromContext(r.Context()).Info("chirp not found", "chirp_id", chirpID)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Chirp not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("database error", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		if chirp.UserID != userID {
+			logging.FromContext(r.Context()).Warn("chirp edit not allowed")
+			problem.Error(w, r, http.StatusForbidden, problem.Forbidden, "You can only edit your own chirps.")
+			return
+		}
+		if chirp.Status == "scheduled" {
+			logging.FromContext(r.Context()).Info("chirp is scheduled", "chirp_id", chirpID)
+			problem.Error(w, r, http.StatusConflict, problem.Conflict, "Scheduled chirps can't be edited; cancel and schedule them again.")
+			return
+		}
+		if time.Since(chirp.CreatedAt) > cfg.ChirpLimits.EditWindow {
+			logging.FromContext(r.Context()).Info("edit window closed", "created_at", chirp.CreatedAt)
+			problem.Error(w, r, http.StatusForbidden, problem.Forbidden, "This chirp can no longer be edited.")
+			return
+		}
+
+		user, err := cfg.DB.GetUserByID(r.Context(), userID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get chirp author", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		// a chirp with media may have no text, like when it was posted.
+		media, err := cfg.DB.GetChirpMedia(r.Context(), []uuid.UUID{chirpID})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get chirp media", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		var v validate.Validator
+		filtered, err := checkChirpBody(r.Context(), cfg, &v, user, req.Body, len(media) > 0)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to load filters", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		if !v.Valid() {
+			logging.FromContext(r.Context()).Info("invalid chirp", "errors", v.Errors)
+			v.Write(w, r)
+			return
+		}
+
+		err = cfg.InTx(r.Context(), func(q *database.Queries) error {
+			current, err := q.GetChirpForUpdate(r.Context(), chirpID)
+			if err != nil {
+				return err
+			}
+
+			// saving the same text again isn't an edit.
+			if current.Body == filtered.Body {
+				return nil
+			}
+
+			err = q.CreateChirpRevision(r.Context(), database.CreateChirpRevisionParams{
+				ChirpID:   chirpID,
+				CreatedAt: current.UpdatedAt,
+				Body:      current.Body,
+			})
+			if err != nil {
+				return err
+			}
+
+			if _, err := q.UpdateChirpBody(r.Context(), database.UpdateChirpBodyParams{
+				ID:   chirpID,
+				Body: filtered.Body,
+			}); err != nil {
+				return err
+			}
+
+			return flagChirp(r.Context(), q, chirpID, filtered.Flagged)
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				// deleted since we looked it up.
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Chirp not found.")
+				return
+			}
+			logging.FromContext(r.Context()).Error("failed to edit chirp", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		logging.FromContext(r.Context()).Info("chirp edited", "chirp_id", chirpID)
+
+		row, err := cfg.DB.GetChirpWithAuthor(r.Context(), database.GetChirpWithAuthorParams{ID: chirpID})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get edited chirp", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		res := []chirpResponse{newChirpResponse(row)}
+		if err := withMedia(r.Context(), cfg.DB, res); err != nil {
+			logging.FromContext(r.Context()).Error("failed to get chirp media", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		if err := withQuotes(r.Context(), cfg.DB, uuid.NullUUID{UUID: userID, Valid: true}, res); err != nil {
+			logging.FromContext(r.Context()).Error("failed to get quotes", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(res[0]); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+		}
+	})
+}
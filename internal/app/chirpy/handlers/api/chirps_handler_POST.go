@@ -1,16 +1,23 @@
 package api
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/johndosdos/chirpy/internal/app/chirpy"
-	"github.com/johndosdos/chirpy/internal/auth"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/filter"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
 	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+	"github.com/johndosdos/chirpy/internal/metrics"
 )
 
 func ProcessChirp(cfg *chirpy.ApiConfig) http.Handler {
@@ -20,135 +27,302 @@ func ProcessChirp(cfg *chirpy.ApiConfig) http.Handler {
 		// we return a response after encoding when the data being sent is
 		// valid or invalid; true or false and http status codes.
 
-		type response struct {
-			Id        uuid.UUID `json:"id"`
-			CreatedAt time.Time `json:"created_at"`
-			UpdatedAt time.Time `json:"updated_at"`
-			UserId    uuid.UUID `json:"user_id"`
-			Body      string    `json:"body"`
-			Error     string    `json:"error"`
-		}
-
 		type request struct {
-			Body   string    `json:"body"`
-			UserId uuid.UUID `json:"user_id"`
+			Body      string         `json:"body"`
+			UserId    uuid.UUID      `json:"user_id"`
+			Media     []mediaRequest `json:"media"`
+			PublishAt *time.Time     `json:"publish_at"`
+			Quoted    *uuid.UUID     `json:"quoted_chirp_id"`
 		}
 
-		const MAX_CHAR_LEN = 140
 		var req request
-		var storeToDb = func(req *request) (database.Chirp, error) {
-			chirp, err := cfg.DB.AddChirp(r.Context(), database.AddChirpParams{
-				Body:   req.Body,
-				UserID: req.UserId,
-			})
-			if err != nil {
-				return database.Chirp{}, err
-			}
 
-			return chirp, nil
+		// first, decode request body
+		if !validate.DecodeJSON(w, r, &req) {
+			return
 		}
 
-		// first, decode request body
-		decoder := json.NewDecoder(r.Body)
-		err := decoder.Decode(&req)
-		if err != nil {
-			log.Print("Invalid request: ", err)
-			http.Error(w, "Bad request", http.StatusBadRequest)
+		// then, authenticate the user
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
 			return
 		}
 
-		// then, parse HTTP request Authorization header
-		httpBearerToken, err := auth.GetBearerToken(r.Header)
+		// then, set request user ID after JWT validation
+		req.UserId = userID
+
+		// the author decides the length limit, and is embedded in the
+		// response.
+		user, err := cfg.DB.GetUserByID(r.Context(), userID)
 		if err != nil {
-			log.Println(err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("failed to get chirp author", "err", err)
+			problem.InternalError(w, r)
 			return
 		}
 
-		// then, validate JWT
-		userID, err := auth.ValidateJWT(httpBearerToken, cfg.Secret)
+		// then, run the request body through the content filters and
+		// check what's left. a chirp with media may have no text.
+		var v validate.Validator
+		filtered, err := checkChirpBody(r.Context(), cfg, &v, user, req.Body, len(req.Media) > 0)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to load filters", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		validateMedia(&v, req.Media)
+		quotedID, err := checkQuotedChirp(r.Context(), cfg, &v, userID, req.Quoted)
 		if err != nil {
-			log.Println(err)
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			logging.FromContext(r.Context()).Error("failed to get quoted chirp", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		if req.PublishAt != nil {
+			validatePublishAt(&v, *req.PublishAt)
+		}
+		if !v.Valid() {
+			logging.FromContext(r.Context()).Info("invalid chirp", "errors", v.Errors)
+			v.Write(w, r)
 			return
 		}
+		req.Body = filtered.Body
 
-		// then, set request user ID after JWT validation
-		req.UserId = userID
+		// save to databse. the chirp and its media go in together, so a
+		// chirp never shows up with half its images.
+		var chirp database.Chirp
+		var attached []mediaResponse
+		err = cfg.InTx(r.Context(), func(q *database.Queries) error {
+			chirp, attached, err = createChirp(r.Context(), q, req.UserId, req.Body, req.Media, req.PublishAt, quotedID, filtered.Flagged)
+			return err
+		})
+		if err != nil {
+			if mediaUnavailable(err) {
+				logging.FromContext(r.Context()).Info("media unavailable", "err", err)
+				writeMediaUnavailable(w, r)
+				return
+			}
+			logging.FromContext(r.Context()).Error("failed to store chirp", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		if req.PublishAt == nil {
+			metrics.ChirpsCreated.Inc()
+		}
 
-		// then, sanitize the request body by checking for profanity.
-		sanitizedBody := sanitizeBody(req.Body)
-		req.Body = sanitizedBody
+		res := []chirpResponse{ownChirpResponse(user, chirp)}
+		res[0].Media = attached
+		if err := withQuotes(r.Context(), cfg.DB, uuid.NullUUID{UUID: userID, Valid: true}, res); err != nil {
+			logging.FromContext(r.Context()).Error("failed to get quoted chirp", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
 
-		// then, return a 400 http error (bad request) if char > 140. else,
-		// return 200 (OK)
+		//  send response
 		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
 
-		// AVOID MAGIC NUMBERS, i.e., MAX_CHAR_LEN
-		if len(sanitizedBody) <= MAX_CHAR_LEN {
+		encoder := json.NewEncoder(w)
+		err = encoder.Encode(res[0])
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+			return
+		}
+	})
+}
 
-			// save to databse
-			chirp, err := storeToDb(&req)
-			if err != nil {
-				log.Println("Unexpected error: ", err)
-				http.Error(w, "Internal server error", http.StatusInternalServerError)
-				return
-			}
+// checkChirpBody runs body through the content filters and adds an error to
+// v if the result is empty (unless the chirp has media), too long for user
+// or has rejected words. masked words are replaced in the returned body,
+// the rest of it is left as is. the length is that of the body as sent, so
+// it matches what the client counted before masking. the error is only for
+// failing to load the filters.
+func checkChirpBody(ctx context.Context, cfg *chirpy.ApiConfig, v *validate.Validator, user database.User, body string, hasMedia bool) (filter.Result, error) {
+	filters, err := chirpy.Filters(ctx, cfg.DB)
+	if err != nil {
+		return filter.Result{}, err
+	}
+	filtered := filters.Filter(body)
 
-			//  send response
-			w.WriteHeader(http.StatusCreated)
-
-			encoder := json.NewEncoder(w)
-			err = encoder.Encode(response{
-				Id:        chirp.ID,
-				CreatedAt: chirp.CreatedAt,
-				UpdatedAt: chirp.UpdatedAt,
-				UserId:    chirp.UserID,
-				Body:      chirp.Body,
-			})
-			if err != nil {
-				log.Println("Failed to encode response json: ", err)
-				http.Error(w, "Internal server error", http.StatusInternalServerError)
-				return
-			}
-		} else {
-			w.WriteHeader(http.StatusBadRequest)
-
-			encoder := json.NewEncoder(w)
-			err := encoder.Encode(response{
-				Error: "Chirp is too long. Max character length is 140.",
-				Body:  sanitizedBody,
-			})
-			if err != nil {
-				log.Println("Chirp is more than 140 chars: ", err)
-				http.Error(w, "Bad request", http.StatusBadRequest)
-				return
-			}
-		}
+	if !hasMedia {
+		v.Required("body", filtered.Body)
+	}
+	v.ChirpBody("body", body, cfg.ChirpLimits.MaxLengthFor(user), cfg.ChirpLimits.URLLength)
+	v.Check(len(filtered.Rejected) == 0, "body", "not_allowed", "contains words that aren't allowed")
 
+	return filtered, nil
+}
+
+// createChirp stores a chirp that passed checkChirpBody, with its media and
+// flags. with publishAt, it's saved as scheduled and published later by
+// PublishScheduledChirps. run it in a transaction.
+func createChirp(ctx context.Context, q *database.Queries, userID uuid.UUID, body string, media []mediaRequest, publishAt *time.Time, quotedID uuid.NullUUID, flagged []filter.Match) (database.Chirp, []mediaResponse, error) {
+	var chirp database.Chirp
+	var err error
+	if publishAt != nil {
+		chirp, err = q.ScheduleChirp(ctx, database.ScheduleChirpParams{
+			Body:          body,
+			UserID:        userID,
+			PublishAt:     sql.NullTime{Time: *publishAt, Valid: true},
+			QuotedChirpID: quotedID,
+		})
+	} else {
+		chirp, err = q.AddChirp(ctx, database.AddChirpParams{
+			Body:          body,
+			UserID:        userID,
+			QuotedChirpID: quotedID,
+		})
+	}
+	if err != nil {
+		return database.Chirp{}, nil, err
+	}
+
+	attached, err := attachMedia(ctx, q, chirp.ID, userID, media)
+	if err != nil {
+		return database.Chirp{}, nil, err
+	}
+
+	if err := flagChirp(ctx, q, chirp.ID, flagged); err != nil {
+		return database.Chirp{}, nil, err
+	}
+	return chirp, attached, nil
+}
+
+// checkQuotedChirp adds an error to v unless the chirp with id, if any, is
+// one the user can see and so quote. the error is only for failing to look
+// it up.
+func checkQuotedChirp(ctx context.Context, cfg *chirpy.ApiConfig, v *validate.Validator, userID uuid.UUID, id *uuid.UUID) (uuid.NullUUID, error) {
+	if id == nil {
+		return uuid.NullUUID{}, nil
+	}
+
+	_, err := cfg.DB.GetChirpWithAuthor(ctx, database.GetChirpWithAuthorParams{
+		ID:       *id,
+		ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		v.Add("quoted_chirp_id", "not_found", "must be a chirp you can see")
+		return uuid.NullUUID{}, nil
+	}
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	return uuid.NullUUID{UUID: *id, Valid: true}, nil
+}
+
+// MaxScheduleAhead is how far ahead a chirp can be scheduled.
+const MaxScheduleAhead = 365 * 24 * time.Hour
+
+// validatePublishAt checks the publish_at of a chirp being scheduled or
+// rescheduled.
+func validatePublishAt(v *validate.Validator, publishAt time.Time) {
+	now := time.Now()
+	v.Check(publishAt.After(now), "publish_at", "in_past", "must be in the future")
+	v.Check(publishAt.Before(now.Add(MaxScheduleAhead)), "publish_at", "too_far", "must be within a year")
+}
+
+const (
+	// MaxMediaPerChirp is how many images a chirp can have.
+	MaxMediaPerChirp = 4
+
+	// MaxAltTextLength leaves room for a proper description of the image.
+	MaxAltTextLength = 1000
+)
+
+// mediaRequest refers to an upload from POST /api/media.
+type mediaRequest struct {
+	ID      uuid.UUID `json:"id"`
+	AltText string    `json:"alt_text"`
+}
+
+// errMediaUnavailable means some media doesn't exist, isn't the user's or
+// is already on another chirp. we don't say which, so IDs can't be probed.
+var errMediaUnavailable = errors.New("media unavailable")
+
+// mediaUnavailable reports whether err from createChirp is about the
+// media, which is the client's fault.
+func mediaUnavailable(err error) bool {
+	return errors.Is(err, errMediaUnavailable) || database.IsUniqueViolation(err, database.ChirpMediaMediaIDKey)
+}
+
+func writeMediaUnavailable(w http.ResponseWriter, r *http.Request) {
+	problem.Fields(w, r, "Media can't be attached.", problem.FieldError{
+		Field:   "media",
+		Code:    "unavailable",
+		Message: "must be your own uploads that aren't attached to another chirp",
 	})
 }
 
-func sanitizeBody(body string) string {
-	// the reason for using structs as values is because nil structs
-	// don't allocate memory. bool as values, on the other hand, will
-	// allocate memory. we only need this map for existence checks.
-	// also maps have fast lookups.
+func validateMedia(v *validate.Validator, items []mediaRequest) {
+	v.Check(len(items) <= MaxMediaPerChirp, "media", "too_many", fmt.Sprintf("must have at most %d items", MaxMediaPerChirp))
 
-	// profane words with punctuations are not sanitized.
-	profanityMap := map[string]struct{}{
-		"kerfuffle": {},
-		"sharbert":  {},
-		"fornax":    {},
+	seen := make(map[uuid.UUID]bool, len(items))
+	for i, item := range items {
+		field := fmt.Sprintf("media[%d]", i)
+		v.Check(item.ID != uuid.Nil, field+".id", "required", "is required")
+		v.Check(!seen[item.ID], field+".id", "duplicate", "is already in the list")
+		v.MaxLength(field+".alt_text", item.AltText, MaxAltTextLength)
+		seen[item.ID] = true
 	}
+}
 
-	splitBody := strings.Fields(body)
-	for pos, word := range splitBody {
-		loword := strings.ToLower(word)
-		if _, ok := profanityMap[loword]; ok {
-			splitBody[pos] = "****"
+// attachMedia attaches items to the chirp in the order given, returning
+// them as they should appear in the response.
+func attachMedia(ctx context.Context, q *database.Queries, chirpID, userID uuid.UUID, items []mediaRequest) ([]mediaResponse, error) {
+	attached := []mediaResponse{}
+	if len(items) == 0 {
+		return attached, nil
+	}
+
+	ids := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+
+	rows, err := q.GetUnattachedMedia(ctx, database.GetUnattachedMediaParams{
+		Ids:    ids,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) != len(items) {
+		return nil, errMediaUnavailable
+	}
+
+	byID := make(map[uuid.UUID]database.Medium, len(rows))
+	for _, row := range rows {
+		byID[row.ID] = row
+	}
+
+	for i, item := range items {
+		altText := strings.TrimSpace(item.AltText)
+		err := q.AttachMedia(ctx, database.AttachMediaParams{
+			ChirpID:  chirpID,
+			MediaID:  item.ID,
+			Position: int16(i),
+			AltText:  altText,
+		})
+		if err != nil {
+			return nil, err
 		}
+		attached = append(attached, newMediaResponse(byID[item.ID], &altText))
 	}
 
-	return strings.Join(splitBody, " ")
+	return attached, nil
+}
+
+// flagChirp records the flag rules that matched a chirp, for moderators to
+// review.
+func flagChirp(ctx context.Context, q *database.Queries, chirpID uuid.UUID, matches []filter.Match) error {
+	for _, m := range matches {
+		ruleID, err := uuid.Parse(m.Rule.ID)
+		err = q.CreateFilterFlag(ctx, database.CreateFilterFlagParams{
+			ChirpID: chirpID,
+			RuleID:  uuid.NullUUID{UUID: ruleID, Valid: err == nil},
+			Matched: m.Text,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+)
+
+func TestValidatePublishAt(t *testing.T) {
+	cases := []struct {
+		name      string
+		publishAt time.Time
+		want      string // error code, "" if valid
+	}{
+		{"next minute", time.Now().Add(time.Minute), ""},
+		{"almost a year", time.Now().Add(MaxScheduleAhead - time.Hour), ""},
+		{"past", time.Now().Add(-time.Minute), "in_past"},
+		{"now", time.Now(), "in_past"},
+		{"over a year", time.Now().Add(MaxScheduleAhead + time.Hour), "too_far"},
+	}
+
+	for _, c := range cases {
+		var v validate.Validator
+		validatePublishAt(&v, c.publishAt)
+		if c.want == "" {
+			if !v.Valid() {
+				t.Errorf("%s: expected valid, got %+v\n", c.name, v.Errors)
+			}
+			continue
+		}
+		if len(v.Errors) != 1 || v.Errors[0].Field != "publish_at" || v.Errors[0].Code != c.want {
+			t.Errorf("%s: expected publish_at %s, got %+v\n", c.name, c.want, v.Errors)
+		}
+	}
+}
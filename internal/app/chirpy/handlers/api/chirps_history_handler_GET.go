@@ -0,0 +1,72 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// GetChirpHistory lists every version of a chirp, newest (the current one)
+// first. it's as visible as the chirp itself.
+func GetChirpHistory(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type version struct {
+			Body      string    `json:"body"`
+			CreatedAt time.Time `json:"created_at"`
+		}
+
+		chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+		if err != nil {
+			logging.FromContext(r.Context()).Info("invalid chirp ID", "err", err)
+			problem.Error(w, r, http.StatusBadRequest, problem.InvalidID, "Chirp ID must be a UUID.")
+			return
+		}
+
+		viewerID, ok := viewer(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		row, err := cfg.DB.GetChirpWithAuthor(r.Context(), database.GetChirpWithAuthorParams{
+			ID:       chirpID,
+			ViewerID: viewerID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logging.FromContext(r.Context()).Info("chirp not found", "err", err)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Chirp not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("database error", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		revisions, err := cfg.DB.GetChirpRevisions(r.Context(), chirpID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get chirp revisions", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		versions := make([]version, 0, len(revisions)+1)
+		versions = append(versions, version{Body: row.Chirp.Body, CreatedAt: row.Chirp.UpdatedAt})
+		for _, rev := range revisions {
+			versions = append(versions, version{Body: rev.Body, CreatedAt: rev.CreatedAt})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(versions); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+		}
+	})
+}
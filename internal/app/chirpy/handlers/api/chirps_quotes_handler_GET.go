@@ -0,0 +1,132 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// GetChirpQuotes lists the chirps quoting a chirp, newest first, leaving
+// out the same ones GET /api/chirps would for the viewer. ?limit= defaults
+// to 50, at most 200. for the next page, pass the created_at and id of the
+// last one as ?before= and ?before_id=; quotes published together share a
+// created_at, so before alone would skip the rest of them.
+func GetChirpQuotes(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+		if err != nil {
+			logging.FromContext(r.Context()).Info("invalid chirp ID", "err", err)
+			problem.Error(w, r, http.StatusBadRequest, problem.InvalidID, "Chirp ID must be a UUID.")
+			return
+		}
+
+		viewerID, ok := viewer(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		params := database.GetQuotesParams{
+			QuotedChirpID: uuid.NullUUID{UUID: chirpID, Valid: true},
+			ViewerID:      viewerID,
+		}
+		if s := r.URL.Query().Get("before"); s != "" {
+			before, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				problem.Fields(w, r, "Invalid query.", problem.FieldError{Field: "before", Code: "invalid", Message: "must be an RFC 3339 time"})
+				return
+			}
+			params.Before = sql.NullTime{Time: before, Valid: true}
+		}
+		if s := r.URL.Query().Get("before_id"); s != "" {
+			beforeID, err := uuid.Parse(s)
+			if err != nil || !params.Before.Valid {
+				problem.Fields(w, r, "Invalid query.", problem.FieldError{Field: "before_id", Code: "invalid", Message: "must be a UUID, together with before"})
+				return
+			}
+			params.BeforeID = uuid.NullUUID{UUID: beforeID, Valid: true}
+		}
+		params.RowLimit, ok = validate.Limit(w, r, 50, 200)
+		if !ok {
+			return
+		}
+
+		// the quoted chirp has to be one the viewer can see.
+		_, err = cfg.DB.GetChirpWithAuthor(r.Context(), database.GetChirpWithAuthorParams{
+			ID:       chirpID,
+			ViewerID: viewerID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logging.FromContext(r.Context()).Info("chirp not found", "err", err)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Chirp not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("database error", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		muter, err := muterFor(r.Context(), cfg, viewerID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get muted words", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		// muted words can only be matched here, so keep reading pages until
+		// there's a full one left after them, or no more quotes.
+		limit := params.RowLimit
+		chirps := []chirpResponse{}
+		for int32(len(chirps)) < limit {
+			rows, err := cfg.DB.GetQuotes(r.Context(), params)
+			if err != nil {
+				logging.FromContext(r.Context()).Error("failed to get quotes", "err", err)
+				problem.InternalError(w, r)
+				return
+			}
+
+			for _, row := range rows {
+				if int32(len(chirps)) == limit {
+					break
+				}
+				if row.Chirp.UserID != viewerID.UUID && muter.Mutes(row.Chirp.Body) {
+					continue
+				}
+				chirps = append(chirps, newChirpResponse(database.GetChirpWithAuthorRow(row)))
+			}
+
+			if int32(len(rows)) < params.RowLimit {
+				break
+			}
+			last := rows[len(rows)-1].Chirp
+			params.Before = sql.NullTime{Time: last.CreatedAt, Valid: true}
+			params.BeforeID = uuid.NullUUID{UUID: last.ID, Valid: true}
+		}
+
+		if err := withMedia(r.Context(), cfg.DB, chirps); err != nil {
+			logging.FromContext(r.Context()).Error("failed to get chirp media", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		if err := withQuotes(r.Context(), cfg.DB, viewerID, chirps); err != nil {
+			logging.FromContext(r.Context()).Error("failed to get quotes", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(chirps); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+		}
+	})
+}
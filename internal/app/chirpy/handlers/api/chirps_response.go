@@ -0,0 +1,186 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/database"
+)
+
+// author is the compact user embedded in every chirp, enough to render the
+// chirp without looking up its author separately.
+type author struct {
+	ID          uuid.UUID `json:"id"`
+	Handle      string    `json:"handle"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url"`
+}
+
+// chirpResponse is how chirps look in every response. a quote whose quoted
+// chirp the viewer can't see has a QuotedChirpID but no QuotedChirp.
+type chirpResponse struct {
+	ID            uuid.UUID       `json:"id"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	Body          string          `json:"body"`
+	EditedAt      *time.Time      `json:"edited_at"`
+	Status        string          `json:"status"`
+	PublishAt     *time.Time      `json:"publish_at"`
+	Author        author          `json:"author"`
+	Media         []mediaResponse `json:"media"`
+	QuotedChirpID *uuid.UUID      `json:"quoted_chirp_id"`
+	QuotedChirp   *chirpResponse  `json:"quoted_chirp"`
+	QuoteCount    int64           `json:"quote_count"`
+}
+
+// mediaResponse is an uploaded image, on its own after an upload or as
+// attached to a chirp, in which case AltText is set.
+type mediaResponse struct {
+	ID           uuid.UUID `json:"id"`
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	Width        int32     `json:"width"`
+	Height       int32     `json:"height"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	AltText      *string   `json:"alt_text,omitempty"`
+}
+
+func newMediaResponse(m database.Medium, altText *string) mediaResponse {
+	return mediaResponse{
+		ID:           m.ID,
+		ContentType:  m.ContentType,
+		Size:         m.SizeBytes,
+		Width:        m.Width,
+		Height:       m.Height,
+		URL:          "/api/media/" + m.ID.String(),
+		ThumbnailURL: "/api/media/" + m.ID.String() + "/thumbnail",
+		AltText:      altText,
+	}
+}
+
+// newChirpResponse takes the row of GetChirpWithAuthor. rows of the other
+// *WithAuthor(s) queries have the same fields and can be converted to it.
+func newChirpResponse(row database.GetChirpWithAuthorRow) chirpResponse {
+	res := chirpResponse{
+		ID:        row.Chirp.ID,
+		CreatedAt: row.Chirp.CreatedAt,
+		UpdatedAt: row.Chirp.UpdatedAt,
+		Body:      row.Chirp.Body,
+		EditedAt:  nullTime(row.Chirp.EditedAt),
+		Status:    row.Chirp.Status,
+		PublishAt: nullTime(row.Chirp.PublishAt),
+		Author: author{
+			ID:          row.Chirp.UserID,
+			Handle:      row.AuthorHandle,
+			DisplayName: row.AuthorDisplayName,
+			AvatarURL:   row.AuthorAvatarUrl,
+		},
+		Media: []mediaResponse{},
+	}
+	if row.Chirp.QuotedChirpID.Valid {
+		res.QuotedChirpID = &row.Chirp.QuotedChirpID.UUID
+	}
+	return res
+}
+
+// withMedia fills in the media of chirps with a single query, however many
+// chirps there are.
+func withMedia(ctx context.Context, db *database.Queries, chirps []chirpResponse) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(chirps))
+	index := make(map[uuid.UUID]int, len(chirps))
+	for i, chirp := range chirps {
+		ids[i] = chirp.ID
+		index[chirp.ID] = i
+	}
+
+	rows, err := db.GetChirpMedia(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	// rows come ordered by position.
+	for _, row := range rows {
+		i := index[row.ChirpID]
+		chirps[i].Media = append(chirps[i].Media, newMediaResponse(row.Medium, &row.AltText))
+	}
+
+	return nil
+}
+
+// withQuotes fills in the quote counts of chirps and the chirps they quote,
+// as seen by viewerID, with a couple of queries however many chirps there
+// are. counts leave out the same quotes as GET /api/chirps/{chirpID}/quotes
+// except for muted words, which only Go can match. quoted chirps come with
+// their media but not with what they quote in turn, so quotes of quotes
+// don't nest forever.
+func withQuotes(ctx context.Context, db *database.Queries, viewerID uuid.NullUUID, chirps []chirpResponse) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(chirps))
+	var quotedIDs []uuid.UUID
+	for i, chirp := range chirps {
+		ids[i] = chirp.ID
+		if chirp.QuotedChirpID != nil {
+			quotedIDs = append(quotedIDs, *chirp.QuotedChirpID)
+		}
+	}
+
+	counts, err := db.CountQuotes(ctx, database.CountQuotesParams{
+		Ids:      ids,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		return err
+	}
+	byID := make(map[uuid.UUID]int64, len(counts))
+	for _, row := range counts {
+		byID[row.ChirpID] = row.QuoteCount
+	}
+	for i := range chirps {
+		chirps[i].QuoteCount = byID[chirps[i].ID]
+	}
+
+	if len(quotedIDs) == 0 {
+		return nil
+	}
+
+	rows, err := db.GetQuotedChirps(ctx, database.GetQuotedChirpsParams{
+		Ids:      quotedIDs,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		return err
+	}
+
+	quoted := make([]chirpResponse, len(rows))
+	for i, row := range rows {
+		quoted[i] = newChirpResponse(database.GetChirpWithAuthorRow(row))
+	}
+	if err := withMedia(ctx, db, quoted); err != nil {
+		return err
+	}
+
+	index := make(map[uuid.UUID]int, len(quoted))
+	for i, chirp := range quoted {
+		index[chirp.ID] = i
+	}
+	for i, chirp := range chirps {
+		if chirp.QuotedChirpID == nil {
+			continue
+		}
+		if j, ok := index[*chirp.QuotedChirpID]; ok {
+			q := quoted[j]
+			chirps[i].QuotedChirp = &q
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// DeleteDraft discards one of the user's drafts. other users' drafts are
+// not found.
+func DeleteDraft(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		draftID, ok := parseDraftID(w, r)
+		if !ok {
+			return
+		}
+
+		n, err := cfg.DB.DeleteDraft(r.Context(), database.DeleteDraftParams{
+			ID:     draftID,
+			UserID: userID,
+		})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to delete draft", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		if n == 0 {
+			logging.FromContext(r.Context()).Info("draft not found", "draft_id", draftID)
+			problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Draft not found.")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
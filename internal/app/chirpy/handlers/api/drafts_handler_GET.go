@@ -0,0 +1,78 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// GetDrafts lists the user's drafts, most recently changed first.
+func GetDrafts(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		rows, err := cfg.DB.GetDrafts(r.Context(), userID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get drafts", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		drafts := make([]draftResponse, len(rows))
+		for i, row := range rows {
+			drafts[i] = newDraftResponse(row)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(drafts); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+		}
+	})
+}
+
+// GetDraft returns one of the user's drafts. other users' drafts are not
+// found.
+func GetDraft(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		draftID, ok := parseDraftID(w, r)
+		if !ok {
+			return
+		}
+
+		draft, err := cfg.DB.GetDraft(r.Context(), database.GetDraftParams{
+			ID:     draftID,
+			UserID: userID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logging.FromContext(r.Context()).Info("draft not found", "draft_id", draftID)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Draft not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("database error", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(newDraftResponse(draft)); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+		}
+	})
+}
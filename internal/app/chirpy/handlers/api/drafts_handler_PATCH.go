@@ -0,0 +1,86 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// UpdateDraft changes the body and/or media of one of the user's drafts.
+// fields left out of the request are kept.
+func UpdateDraft(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type request struct {
+			Body  *string         `json:"body"`
+			Media *[]mediaRequest `json:"media"`
+		}
+
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		draftID, ok := parseDraftID(w, r)
+		if !ok {
+			return
+		}
+
+		var req request
+		if !validate.DecodeJSON(w, r, &req) {
+			return
+		}
+
+		var draft database.Draft
+		err := cfg.InTx(r.Context(), func(q *database.Queries) error {
+			current, err := q.GetDraftForUpdate(r.Context(), database.GetDraftForUpdateParams{
+				ID:     draftID,
+				UserID: userID,
+			})
+			if err != nil {
+				return err
+			}
+
+			params := database.UpdateDraftParams{
+				ID:     draftID,
+				UserID: userID,
+				Body:   current.Body,
+				Media:  current.Media,
+			}
+			if req.Body != nil {
+				params.Body = *req.Body
+			}
+			if req.Media != nil {
+				params.Media, err = marshalDraftMedia(*req.Media)
+				if err != nil {
+					return err
+				}
+			}
+
+			draft, err = q.UpdateDraft(r.Context(), params)
+			return err
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logging.FromContext(r.Context()).Info("draft not found", "draft_id", draftID)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Draft not found.")
+				return
+			}
+			logging.FromContext(r.Context()).Error("failed to update draft", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(newDraftResponse(draft)); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+		}
+	})
+}
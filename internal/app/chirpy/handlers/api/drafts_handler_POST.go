@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// MaxDrafts is how many drafts a user can keep. drafts aren't checked
+// until published, so this and the request size are all that stops them
+// from growing.
+const MaxDrafts = 100
+
+var errTooManyDrafts = errors.New("too many drafts")
+
+// draftResponse is a draft as saved. media is the list it was saved with,
+// as is.
+type draftResponse struct {
+	ID        uuid.UUID       `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Body      string          `json:"body"`
+	Media     json.RawMessage `json:"media"`
+}
+
+func newDraftResponse(d database.Draft) draftResponse {
+	return draftResponse{
+		ID:        d.ID,
+		CreatedAt: d.CreatedAt,
+		UpdatedAt: d.UpdatedAt,
+		Body:      d.Body,
+		Media:     d.Media,
+	}
+}
+
+// CreateDraft saves an unfinished chirp. nothing about it is checked until
+// it's published with PublishDraft.
+func CreateDraft(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type request struct {
+			Body  string         `json:"body"`
+			Media []mediaRequest `json:"media"`
+		}
+
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		var req request
+		if !validate.DecodeJSON(w, r, &req) {
+			return
+		}
+
+		media, err := marshalDraftMedia(req.Media)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode draft media", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		var draft database.Draft
+		err = cfg.InTx(r.Context(), func(q *database.Queries) error {
+			count, err := q.CountDrafts(r.Context(), userID)
+			if err != nil {
+				return err
+			}
+			if count >= MaxDrafts {
+				return errTooManyDrafts
+			}
+
+			draft, err = q.CreateDraft(r.Context(), database.CreateDraftParams{
+				UserID: userID,
+				Body:   req.Body,
+				Media:  media,
+			})
+			return err
+		})
+		if err != nil {
+			if errors.Is(err, errTooManyDrafts) {
+				logging.FromContext(r.Context()).Info("too many drafts")
+				problem.Error(w, r, http.StatusConflict, problem.Conflict, fmt.Sprintf("You can't have more than %d drafts, publish or delete some first.", MaxDrafts))
+				return
+			}
+			logging.FromContext(r.Context()).Error("failed to create draft", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(newDraftResponse(draft)); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+		}
+	})
+}
+
+// marshalDraftMedia encodes media for the drafts.media column. no media is
+// [], not null.
+func marshalDraftMedia(media []mediaRequest) (json.RawMessage, error) {
+	if media == nil {
+		media = []mediaRequest{}
+	}
+	return json.Marshal(media)
+}
+
+// parseDraftID writes a 400 if the draftID path value isn't a UUID.
+func parseDraftID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		logging.FromContext(r.Context()).Info("invalid draft ID", "err", err)
+		problem.Error(w, r, http.StatusBadRequest, problem.InvalidID, "Draft ID must be a UUID.")
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+	"github.com/johndosdos/chirpy/internal/metrics"
+)
+
+// errDraftChanged means the draft was saved again between being checked
+// and being published.
+var errDraftChanged = errors.New("draft changed")
+
+// PublishDraft turns one of the user's drafts into a chirp. the draft goes
+// through the same checks as POST /api/chirps, and is deleted in the same
+// transaction the chirp is created in, so it's published exactly once.
+func PublishDraft(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		draftID, ok := parseDraftID(w, r)
+		if !ok {
+			return
+		}
+
+		draft, err := cfg.DB.GetDraft(r.Context(), database.GetDraftParams{
+			ID:     draftID,
+			UserID: userID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logging.FromContext(r.Context()).Info("draft not found", "draft_id", draftID)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Draft not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("database error", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		var media []mediaRequest
+		if err := json.Unmarshal(draft.Media, &media); err != nil {
+			logging.FromContext(r.Context()).Error("failed to decode draft media", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		user, err := cfg.DB.GetUserByID(r.Context(), userID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get chirp author", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		var v validate.Validator
+		filtered, err := checkChirpBody(r.Context(), cfg, &v, user, draft.Body, len(media) > 0)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to load filters", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		validateMedia(&v, media)
+		if !v.Valid() {
+			logging.FromContext(r.Context()).Info("invalid draft", "errors", v.Errors)
+			v.Write(w, r)
+			return
+		}
+
+		var chirp database.Chirp
+		var attached []mediaResponse
+		err = cfg.InTx(r.Context(), func(q *database.Queries) error {
+			current, err := q.GetDraftForUpdate(r.Context(), database.GetDraftForUpdateParams{
+				ID:     draftID,
+				UserID: userID,
+			})
+			if err != nil {
+				return err
+			}
+			if !current.UpdatedAt.Equal(draft.UpdatedAt) {
+				return errDraftChanged
+			}
+
+			chirp, attached, err = createChirp(r.Context(), q, userID, filtered.Body, media, nil, uuid.NullUUID{}, filtered.Flagged)
+			if err != nil {
+				return err
+			}
+
+			_, err = q.DeleteDraft(r.Context(), database.DeleteDraftParams{
+				ID:     draftID,
+				UserID: userID,
+			})
+			return err
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				// published or deleted by another request in the meantime.
+				logging.FromContext(r.Context()).Info("draft not found", "draft_id", draftID)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Draft not found.")
+			case errors.Is(err, errDraftChanged):
+				logging.FromContext(r.Context()).Info("draft changed while publishing", "draft_id", draftID)
+				problem.Error(w, r, http.StatusConflict, problem.Conflict, "The draft changed while publishing, try again.")
+			case mediaUnavailable(err):
+				logging.FromContext(r.Context()).Info("media unavailable", "err", err)
+				writeMediaUnavailable(w, r)
+			default:
+				logging.FromContext(r.Context()).Error("failed to publish draft", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+		metrics.ChirpsCreated.Inc()
+		logging.FromContext(r.Context()).Info("draft published", "draft_id", draftID, "chirp_id", chirp.ID)
+
+		res := ownChirpResponse(user, chirp)
+		res.Media = attached
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+		}
+	})
+}
@@ -1,15 +1,20 @@
 package api
 
 import (
+	"database/sql"
 	"encoding/json"
-	"log"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
 	"github.com/johndosdos/chirpy/internal/auth"
 	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+	"github.com/johndosdos/chirpy/internal/metrics"
 )
 
 func Login(cfg *chirpy.ApiConfig) http.Handler {
@@ -31,28 +36,72 @@ func Login(cfg *chirpy.ApiConfig) http.Handler {
 
 		var req request
 
-		// decode request
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			log.Println("Invalid request: ", err)
-			http.Error(w, "Bad request", http.StatusBadRequest)
+		// decode request. the password policy isn't checked here; whatever
+		// was allowed when the password was set still works.
+		if !validate.DecodeJSON(w, r, &req) {
+			return
+		}
+
+		var v validate.Validator
+		v.Required("email", req.Email)
+		v.Required("password", req.Password)
+		if !v.Valid() {
+			logging.FromContext(r.Context()).Info("invalid request", "errors", v.Errors)
+			v.Write(w, r)
 			return
 		}
 
 		// get user info by email
 		user, err := cfg.DB.GetUserByEmail(r.Context(), req.Email)
 		if err != nil {
-			log.Println("Unexpected error: ", err)
-			http.Error(w, "Incorrect email or password", http.StatusUnauthorized)
+			logging.FromContext(r.Context()).Warn("user lookup failed", "err", err)
+			metrics.FailedLogins.Inc()
+			problem.Error(w, r, http.StatusUnauthorized, problem.InvalidCredentials, "Incorrect email or password.")
 			return
 		}
+		logging.SetUserID(r.Context(), user.ID)
 
 		// compare request password to the stored, hashed password
-		if err := auth.CheckPasswordHash(req.Password, user.HashedPassword); err != nil {
-			log.Println("Unexpected error: ", err)
-			http.Error(w, "Incorrect email or password", http.StatusUnauthorized)
+		if err := auth.CheckPasswordHash(r.Context(), req.Password, user.HashedPassword); err != nil {
+			logging.FromContext(r.Context()).Warn("password mismatch", "err", err)
+			metrics.FailedLogins.Inc()
+			problem.Error(w, r, http.StatusUnauthorized, problem.InvalidCredentials, "Incorrect email or password.")
 			return
 		}
 
+		// suspended users can't start new sessions. see "chirpy user suspend".
+		if user.SuspendedAt.Valid {
+			logging.FromContext(r.Context()).Warn("login rejected", "reason", "suspended")
+			metrics.FailedLogins.Inc()
+			problem.Error(w, r, http.StatusForbidden, problem.AccountSuspended, "This account is suspended.")
+			return
+		}
+
+		// logging in during the grace period of DELETE /api/users/me
+		// restores the account. once it's over, the account is as good as
+		// gone even if PurgeDeletedUsers hasn't gotten to it yet.
+		if user.DeleteAfter.Valid {
+			expired := !user.DeleteAfter.Time.After(time.Now())
+			if !expired {
+				user, err = cfg.DB.CancelUserDeletion(r.Context(), user.ID)
+				if errors.Is(err, sql.ErrNoRows) {
+					// the grace period ended in the meantime.
+					expired = true
+				} else if err != nil {
+					logging.FromContext(r.Context()).Error("failed to restore account", "err", err)
+					problem.InternalError(w, r)
+					return
+				}
+			}
+			if expired {
+				logging.FromContext(r.Context()).Warn("login rejected", "reason", "deleted")
+				metrics.FailedLogins.Inc()
+				problem.Error(w, r, http.StatusUnauthorized, problem.InvalidCredentials, "Incorrect email or password.")
+				return
+			}
+			logging.FromContext(r.Context()).Info("account restored")
+		}
+
 		// generate JWT
 		//
 		// note that we need to multipy time.Duration by time.Second since
@@ -61,8 +110,8 @@ func Login(cfg *chirpy.ApiConfig) http.Handler {
 		// access token expire after 1 hour
 		jwt, err := auth.MakeJWT(user.ID, cfg.Secret, time.Duration(1)*time.Hour)
 		if err != nil {
-			log.Println("Unexpected error: ", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("failed to create access token", "err", err)
+			problem.InternalError(w, r)
 			return
 		}
 
@@ -74,8 +123,8 @@ func Login(cfg *chirpy.ApiConfig) http.Handler {
 		// refresh token expire after 60 days
 		newRefreshToken, err := auth.MakeRefreshToken()
 		if err != nil {
-			log.Println("Unexpected error: ", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("failed to generate refresh token", "err", err)
+			problem.InternalError(w, r)
 			return
 		}
 
@@ -87,11 +136,13 @@ func Login(cfg *chirpy.ApiConfig) http.Handler {
 			ExpiresAt: time.Now().Add(60 * 24 * time.Hour),
 		})
 		if err != nil {
-			log.Println("Unexpected error: ", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("failed to store refresh token", "err", err)
+			problem.InternalError(w, r)
 			return
 		}
 
+		metrics.Logins.Inc()
+
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 
@@ -105,8 +156,7 @@ func Login(cfg *chirpy.ApiConfig) http.Handler {
 			RefreshToken: refreshToken.Token,
 			IsChirpyRed:  user.IsChirpyRed,
 		}); err != nil {
-			log.Println("Unexpected error: ", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("failed to encode response", "err", err)
 			return
 		}
 	})
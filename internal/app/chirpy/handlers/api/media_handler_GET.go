@@ -0,0 +1,96 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+	"github.com/johndosdos/chirpy/internal/storage"
+)
+
+// GetMedia serves an uploaded image to whoever can see the chirp it's on,
+// or to the uploader before it's attached. whether they can changes (the
+// chirp gets hidden, someone blocks someone) so responses are private and
+// revalidated every time; the content itself never changes, so that's
+// usually a 304.
+func GetMedia(cfg *chirpy.ApiConfig) http.Handler {
+	return serveMedia(cfg, func(m database.Medium) (string, string) {
+		return m.StorageKey, m.ContentType
+	})
+}
+
+// GetMediaThumbnail serves the thumbnail of an uploaded image.
+func GetMediaThumbnail(cfg *chirpy.ApiConfig) http.Handler {
+	return serveMedia(cfg, func(m database.Medium) (string, string) {
+		return m.ThumbnailKey, m.ThumbnailContentType
+	})
+}
+
+func serveMedia(cfg *chirpy.ApiConfig, blob func(database.Medium) (key, contentType string)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaID, err := uuid.Parse(r.PathValue("mediaID"))
+		if err != nil {
+			logging.FromContext(r.Context()).Info("invalid media ID", "err", err)
+			problem.Error(w, r, http.StatusBadRequest, problem.InvalidID, "Media ID must be a UUID.")
+			return
+		}
+
+		viewerID, ok := viewer(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		m, err := cfg.DB.GetVisibleMedia(r.Context(), database.GetVisibleMediaParams{
+			ID:       mediaID,
+			ViewerID: viewerID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logging.FromContext(r.Context()).Info("media not found", "err", err)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Media not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("database error", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		key, contentType := blob(m)
+		etag := `"` + key + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			w.Header().Set("Cache-Control", "private, no-cache")
+			w.Header().Set("ETag", etag)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		body, err := cfg.Media.Get(r.Context(), key)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				logging.FromContext(r.Context()).Warn("media blob missing", "key", key)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Media not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("failed to get media blob", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+		defer body.Close()
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", "private, no-cache")
+		w.Header().Set("ETag", etag)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+		if _, err := io.Copy(w, body); err != nil {
+			logging.FromContext(r.Context()).Error("failed to write media", "err", err)
+			return
+		}
+	})
+}
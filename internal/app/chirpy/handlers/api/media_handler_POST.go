@@ -0,0 +1,180 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+	"github.com/johndosdos/chirpy/internal/media"
+)
+
+// multipartOverhead is room for the multipart boundaries and headers on top
+// of the file itself.
+const multipartOverhead = 64 << 10
+
+// UploadMedia takes an image as the "file" field of a multipart form. the
+// image is stored without its metadata, next to a thumbnail, and can then
+// be attached to a chirp by its ID.
+func UploadMedia(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		data, ok := readUpload(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		img, err := media.Process(data)
+		if err != nil {
+			switch {
+			case errors.Is(err, media.ErrUnsupportedType):
+				logging.FromContext(r.Context()).Info("unsupported media", "err", err)
+				problem.Error(w, r, http.StatusUnsupportedMediaType, problem.UnsupportedMedia, "File must be a JPEG, PNG, GIF or WebP image.")
+			case errors.Is(err, media.ErrTooLarge):
+				logging.FromContext(r.Context()).Info("image too large", "err", err)
+				problem.Fields(w, r, "Image is too large.", problem.FieldError{
+					Field:   "file",
+					Code:    "too_large",
+					Message: fmt.Sprintf("must be at most %dx%d pixels", media.MaxDimension, media.MaxDimension),
+				})
+			default:
+				logging.FromContext(r.Context()).Error("failed to process media", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		id := uuid.New()
+		storageKey := "media/" + id.String() + "/original"
+		thumbnailKey := "media/" + id.String() + "/thumbnail"
+
+		err = cfg.Media.Put(r.Context(), storageKey, bytes.NewReader(img.Data), int64(len(img.Data)), img.ContentType)
+		if err == nil {
+			err = cfg.Media.Put(r.Context(), thumbnailKey, bytes.NewReader(img.Thumbnail), int64(len(img.Thumbnail)), img.ThumbnailContentType)
+		}
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to store media", "err", err)
+			discardBlobs(r.Context(), cfg, storageKey, thumbnailKey)
+			problem.InternalError(w, r)
+			return
+		}
+
+		m, err := cfg.DB.CreateMedia(r.Context(), database.CreateMediaParams{
+			ID:                   id,
+			UserID:               userID,
+			ContentType:          img.ContentType,
+			SizeBytes:            int64(len(img.Data)),
+			Width:                int32(img.Width),
+			Height:               int32(img.Height),
+			StorageKey:           storageKey,
+			ThumbnailKey:         thumbnailKey,
+			ThumbnailContentType: img.ThumbnailContentType,
+		})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to create media", "err", err)
+			discardBlobs(r.Context(), cfg, storageKey, thumbnailKey)
+			problem.InternalError(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		err = json.NewEncoder(w).Encode(newMediaResponse(m, nil))
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+			return
+		}
+	})
+}
+
+// discardBlobs deletes blobs stored for an upload that failed before it got
+// a media row, so nothing else will ever delete them. if the store fails
+// too, they're queued for CleanUpMedia instead. deleting a blob that was
+// never written is fine.
+func discardBlobs(ctx context.Context, cfg *chirpy.ApiConfig, keys ...string) {
+	// the client may be gone already, the cleanup isn't.
+	ctx = context.WithoutCancel(ctx)
+
+	for _, key := range keys {
+		err := cfg.Media.Delete(ctx, key)
+		if err == nil {
+			continue
+		}
+		logging.FromContext(ctx).Warn("failed to delete media blob", "key", key, "err", err)
+
+		if err := cfg.DB.QueueMediaBlobDeletion(ctx, key); err != nil {
+			logging.FromContext(ctx).Error("failed to queue media blob deletion", "key", key, "err", err)
+		}
+	}
+}
+
+// readUpload returns the contents of the "file" part, writing a problem and
+// returning false if it's missing or bigger than cfg.MediaMaxBytes. the
+// file is read straight off the request rather than through
+// ParseMultipartForm, so nothing is spooled to disk.
+func readUpload(cfg *chirpy.ApiConfig, w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	tooLarge := func() ([]byte, bool) {
+		logging.FromContext(r.Context()).Info("upload too large")
+		problem.Error(w, r, http.StatusRequestEntityTooLarge, problem.BodyTooLarge, fmt.Sprintf("File must be at most %d bytes.", cfg.MediaMaxBytes))
+		return nil, false
+	}
+	missing := func() ([]byte, bool) {
+		problem.Fields(w, r, "Request must be a multipart form with a file.", problem.FieldError{
+			Field:   "file",
+			Code:    "required",
+			Message: "is required",
+		})
+		return nil, false
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, cfg.MediaMaxBytes+multipartOverhead)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		logging.FromContext(r.Context()).Info("invalid multipart form", "err", err)
+		return missing()
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				return tooLarge()
+			}
+			logging.FromContext(r.Context()).Info("no file in multipart form", "err", err)
+			return missing()
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(part, cfg.MediaMaxBytes+1))
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				return tooLarge()
+			}
+			logging.FromContext(r.Context()).Info("failed to read upload", "err", err)
+			return missing()
+		}
+		if int64(len(data)) > cfg.MediaMaxBytes {
+			return tooLarge()
+		}
+		if len(data) == 0 {
+			return missing()
+		}
+		return data, true
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// DeleteMutedWord unmutes a word. other users' muted words are not found.
+func DeleteMutedWord(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		id, err := uuid.Parse(r.PathValue("mutedWordID"))
+		if err != nil {
+			logging.FromContext(r.Context()).Info("invalid muted word ID", "err", err)
+			problem.Error(w, r, http.StatusBadRequest, problem.InvalidID, "Muted word ID must be a UUID.")
+			return
+		}
+
+		n, err := cfg.DB.DeleteMutedWord(r.Context(), database.DeleteMutedWordParams{
+			ID:     id,
+			UserID: userID,
+		})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to delete muted word", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		if n == 0 {
+			logging.FromContext(r.Context()).Info("muted word not found", "muted_word_id", id)
+			problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Muted word not found.")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/filter"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// GetMutedWords lists the user's muted words that haven't expired, most
+// recent first.
+func GetMutedWords(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		rows, err := cfg.DB.GetActiveMutedWords(r.Context(), userID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get muted words", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		words := make([]mutedWordResponse, len(rows))
+		for i, row := range rows {
+			words[i] = newMutedWordResponse(row)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(words); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+		}
+	})
+}
+
+// muterFor builds the Muter of the viewer's active muted words. anonymous
+// viewers get a nil Muter, which mutes nothing.
+func muterFor(ctx context.Context, cfg *chirpy.ApiConfig, viewerID uuid.NullUUID) (*filter.Muter, error) {
+	if !viewerID.Valid {
+		return nil, nil
+	}
+
+	rows, err := cfg.DB.GetActiveMutedWords(ctx, viewerID.UUID)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+
+	words := make([]filter.MutedWord, len(rows))
+	for i, row := range rows {
+		words[i] = filter.MutedWord{Pattern: row.Pattern, Mode: filter.MatchMode(row.Mode)}
+	}
+	return filter.NewMuter(words)
+}
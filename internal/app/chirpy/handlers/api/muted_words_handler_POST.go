@@ -0,0 +1,132 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/filter"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// MaxMutedWords is how many active muted words a user can have. every
+// chirp they read is checked against all of them.
+const MaxMutedWords = 200
+
+var errTooManyMutedWords = errors.New("too many muted words")
+
+type mutedWordResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	Pattern   string     `json:"pattern"`
+	Mode      string     `json:"mode"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func newMutedWordResponse(m database.MutedWord) mutedWordResponse {
+	return mutedWordResponse{
+		ID:        m.ID,
+		CreatedAt: m.CreatedAt,
+		Pattern:   m.Pattern,
+		Mode:      m.Mode,
+		ExpiresAt: nullTime(m.ExpiresAt),
+	}
+}
+
+// CreateMutedWord mutes a word, phrase, hashtag or regular expression for
+// the user, until expires_at if given.
+func CreateMutedWord(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type request struct {
+			Pattern   string     `json:"pattern"`
+			Mode      string     `json:"mode"`
+			ExpiresAt *time.Time `json:"expires_at"`
+		}
+
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		var req request
+		if !validate.DecodeJSON(w, r, &req) {
+			return
+		}
+
+		if req.Mode == "" {
+			req.Mode = string(filter.Word)
+		}
+		if filter.MatchMode(req.Mode) == filter.Word {
+			req.Pattern = strings.TrimSpace(req.Pattern)
+		}
+
+		var v validate.Validator
+		v.Check(req.Mode == string(filter.Word) || req.Mode == string(filter.Regex), "mode", "invalid", "must be word or regex")
+		if err := filter.ValidateMutedWord(req.Pattern, filter.MatchMode(req.Mode)); err != nil && v.Valid() {
+			v.Add("pattern", "invalid", err.Error())
+		}
+		v.Check(req.ExpiresAt == nil || req.ExpiresAt.After(time.Now()), "expires_at", "invalid", "must be in the future")
+		if !v.Valid() {
+			logging.FromContext(r.Context()).Info("invalid muted word", "errors", v.Errors)
+			v.Write(w, r)
+			return
+		}
+
+		var expiresAt sql.NullTime
+		if req.ExpiresAt != nil {
+			expiresAt = sql.NullTime{Time: *req.ExpiresAt, Valid: true}
+		}
+
+		var muted database.MutedWord
+		err := cfg.InTx(r.Context(), func(q *database.Queries) error {
+			if _, err := q.DeleteExpiredMutedWords(r.Context(), userID); err != nil {
+				return err
+			}
+
+			count, err := q.CountActiveMutedWords(r.Context(), userID)
+			if err != nil {
+				return err
+			}
+			if count >= MaxMutedWords {
+				return errTooManyMutedWords
+			}
+
+			muted, err = q.CreateMutedWord(r.Context(), database.CreateMutedWordParams{
+				UserID:    userID,
+				Pattern:   req.Pattern,
+				Mode:      req.Mode,
+				ExpiresAt: expiresAt,
+			})
+			return err
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, errTooManyMutedWords):
+				logging.FromContext(r.Context()).Info("too many muted words")
+				problem.Error(w, r, http.StatusConflict, problem.Conflict, fmt.Sprintf("You can't mute more than %d words, remove some first.", MaxMutedWords))
+			case database.IsUniqueViolation(err, database.MutedWordsUserPatternKey):
+				logging.FromContext(r.Context()).Info("word already muted")
+				problem.Error(w, r, http.StatusConflict, problem.Conflict, "You already muted this.")
+			default:
+				logging.FromContext(r.Context()).Error("failed to create muted word", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(newMutedWordResponse(muted)); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// GetNotifications returns the user's notifications, newest first. with
+// ?unread=true only the ones not marked as read yet. ?limit= defaults to
+// 50, at most 200.
+func GetNotifications(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type notification struct {
+			ID        uuid.UUID  `json:"id"`
+			CreatedAt time.Time  `json:"created_at"`
+			Type      string     `json:"type"`
+			ReportID  *uuid.UUID `json:"report_id,omitempty"`
+			Message   string     `json:"message"`
+			ReadAt    *time.Time `json:"read_at"`
+		}
+
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		query := r.URL.Query()
+		unread, err := strconv.ParseBool(query.Get("unread"))
+		if query.Get("unread") != "" && err != nil {
+			problem.Fields(w, r, "Invalid query.", problem.FieldError{Field: "unread", Code: "invalid", Message: "must be true or false"})
+			return
+		}
+		limit, ok := validate.Limit(w, r, 50, 200)
+		if !ok {
+			return
+		}
+
+		rows, err := cfg.DB.GetNotifications(r.Context(), database.GetNotificationsParams{
+			UserID:     userID,
+			UnreadOnly: unread,
+			RowLimit:   limit,
+		})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get notifications", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		notifications := make([]notification, len(rows))
+		for i, row := range rows {
+			notifications[i] = notification{
+				ID:        row.ID,
+				CreatedAt: row.CreatedAt,
+				Type:      row.Type,
+				Message:   row.Message,
+				ReadAt:    nullTime(row.ReadAt),
+			}
+			if row.ReportID.Valid {
+				notifications[i].ReportID = &row.ReportID.UUID
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(notifications); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+		}
+	})
+}
+
+// ReadNotifications marks all of the user's notifications as read.
+func ReadNotifications(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		if _, err := cfg.DB.MarkNotificationsRead(r.Context(), userID); err != nil {
+			logging.FromContext(r.Context()).Error("failed to mark notifications read", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -2,14 +2,16 @@ package api
 
 import (
 	"database/sql"
-	"encoding/json"
 	"errors"
-	"log"
 	"net/http"
 
 	"github.com/google/uuid"
 	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
 	"github.com/johndosdos/chirpy/internal/auth"
+	"github.com/johndosdos/chirpy/internal/logging"
+	"github.com/johndosdos/chirpy/internal/metrics"
 )
 
 func WebhookHandler(cfg *chirpy.ApiConfig) http.Handler {
@@ -28,24 +30,24 @@ func WebhookHandler(cfg *chirpy.ApiConfig) http.Handler {
 		// match the key in the .env file. if not, return a 401 Unauthorized
 		apiKey, err := auth.GetAPIKey(r.Header)
 		if err != nil {
-			log.Println("failed to get API key: ", err)
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			logging.FromContext(r.Context()).Warn("failed to get API key", "err", err)
+			problem.Error(w, r, http.StatusUnauthorized, problem.Unauthorized, "Missing or invalid API key.")
 			return
 		}
 
 		if apiKey != cfg.PolkaKey {
-			log.Println("invalid api key: ", err)
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			logging.FromContext(r.Context()).Warn("invalid api key", "err", err)
+			problem.Error(w, r, http.StatusUnauthorized, problem.Unauthorized, "Missing or invalid API key.")
 			return
 		}
 
-		err = json.NewDecoder(r.Body).Decode(&req)
-		if err != nil {
-			log.Println("failed to decode request body: ", err)
-			http.Error(w, "Bad request", http.StatusBadRequest)
+		// Polka may add fields to its payload, so unknown ones are fine.
+		if !validate.DecodeJSONAllowUnknown(w, r, &req) {
 			return
 		}
 
+		metrics.WebhookEvents.WithLabelValues(webhookEventLabel(req.Event)).Inc()
+
 		// return 204 No Content if "event" is anything other than "user.upgraded".
 		// we only care if the user has been upgraded to the (hypothetically)
 		// premium plan.
@@ -60,8 +62,8 @@ func WebhookHandler(cfg *chirpy.ApiConfig) http.Handler {
 		// convert userID string to a UUID
 		userID, err := uuid.Parse(req.Data.UserID)
 		if err != nil {
-			log.Println("invalid userID: ", err)
-			http.Error(w, "Bad request", http.StatusBadRequest)
+			logging.FromContext(r.Context()).Info("invalid userID", "err", err)
+			problem.Fields(w, r, "Invalid webhook payload.", problem.FieldError{Field: "data.user_id", Code: "invalid", Message: "must be a UUID"})
 			return
 		}
 
@@ -70,11 +72,11 @@ func WebhookHandler(cfg *chirpy.ApiConfig) http.Handler {
 		_, err = cfg.DB.UpgradeUser(r.Context(), userID)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
-				log.Println("failed to upgrade user: user not found: ", err)
-				http.Error(w, "Not found", http.StatusNotFound)
+				logging.FromContext(r.Context()).Info("user to upgrade not found", "err", err)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "User not found.")
 			} else {
-				log.Println("database error: ", err)
-				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				logging.FromContext(r.Context()).Error("database error", "err", err)
+				problem.InternalError(w, r)
 			}
 			return
 		}
@@ -84,3 +86,14 @@ func WebhookHandler(cfg *chirpy.ApiConfig) http.Handler {
 		w.WriteHeader(http.StatusNoContent)
 	})
 }
+
+// webhookEventLabel keeps the event label bounded; the event name comes
+// from the request body so we can't use it as is.
+func webhookEventLabel(event string) string {
+	switch event {
+	case "user.upgraded":
+		return event
+	default:
+		return "other"
+	}
+}
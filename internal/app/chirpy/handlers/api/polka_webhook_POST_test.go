@@ -0,0 +1,18 @@
+package api
+
+import "testing"
+
+func TestWebhookEventLabel(t *testing.T) {
+	cases := map[string]string{
+		"user.upgraded":  "user.upgraded",
+		"user.deleted":   "other",
+		"":               "other",
+		"user.upgraded ": "other",
+	}
+
+	for event, want := range cases {
+		if got := webhookEventLabel(event); got != want {
+			t.Errorf("%q: expected %q, got %q\n", event, want, got)
+		}
+	}
+}
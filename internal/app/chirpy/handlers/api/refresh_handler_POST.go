@@ -4,12 +4,13 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
-	"log"
 	"net/http"
 	"time"
 
 	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
 	"github.com/johndosdos/chirpy/internal/auth"
+	"github.com/johndosdos/chirpy/internal/logging"
 )
 
 func Refresh(cfg *chirpy.ApiConfig) http.Handler {
@@ -21,8 +22,8 @@ func Refresh(cfg *chirpy.ApiConfig) http.Handler {
 		// get access token from client request
 		token, err := auth.GetBearerToken(r.Header)
 		if err != nil {
-			log.Println("failed to extract bearer token: ", err)
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			logging.FromContext(r.Context()).Warn("failed to extract bearer token", "err", err)
+			problem.Error(w, r, http.StatusUnauthorized, problem.Unauthorized, "Missing or invalid refresh token.")
 			return
 		}
 
@@ -31,34 +32,49 @@ func Refresh(cfg *chirpy.ApiConfig) http.Handler {
 		user, err := cfg.DB.GetUserFromRefreshToken(r.Context(), token)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
-				log.Println("invalid refresh token: not found in database or expired")
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				logging.FromContext(r.Context()).Warn("invalid refresh token", "reason", "not found in database or expired")
+				problem.Error(w, r, http.StatusUnauthorized, problem.Unauthorized, "Missing or invalid refresh token.")
 			} else {
-				log.Println("database error: ", err)
-				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				logging.FromContext(r.Context()).Error("database error", "err", err)
+				problem.InternalError(w, r)
 			}
 			return
 		}
+		logging.SetUserID(r.Context(), user.UserID)
 
 		// check expiration
 		if user.ExpiresAt.Before(time.Now()) {
-			log.Println("invalid refresh token: expired")
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			logging.FromContext(r.Context()).Warn("invalid refresh token", "reason", "expired")
+			problem.Error(w, r, http.StatusUnauthorized, problem.Unauthorized, "Missing or invalid refresh token.")
 			return
 		}
 
 		// check revoke validity
 		if user.RevokedAt.Valid {
-			log.Println("invalid refresh token: revoked")
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			logging.FromContext(r.Context()).Warn("invalid refresh token", "reason", "revoked")
+			problem.Error(w, r, http.StatusUnauthorized, problem.Unauthorized, "Missing or invalid refresh token.")
+			return
+		}
+
+		// suspending a user revokes their refresh tokens, but check anyway in
+		// case one was issued while the suspension was being applied.
+		owner, err := cfg.DB.GetUserByID(r.Context(), user.UserID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("database error", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		if owner.SuspendedAt.Valid {
+			logging.FromContext(r.Context()).Warn("invalid refresh token", "reason", "suspended")
+			problem.Error(w, r, http.StatusForbidden, problem.AccountSuspended, "This account is suspended.")
 			return
 		}
 
 		// create new access token for user after checking
 		tokenString, err := auth.MakeJWT(user.UserID, cfg.Secret, time.Duration(1*time.Hour))
 		if err != nil {
-			log.Println("failed create JWT: ", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("failed to create JWT", "err", err)
+			problem.InternalError(w, r)
 			return
 		}
 
@@ -68,8 +84,7 @@ func Refresh(cfg *chirpy.ApiConfig) http.Handler {
 
 		err = json.NewEncoder(w).Encode(response{Token: tokenString})
 		if err != nil {
-			log.Println("failed to encode JSON response: ", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("failed to encode JSON response", "err", err)
 			return
 		}
 	})
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// reportReasons are the categories a report can have. the reports table
+// has the same list in a check constraint.
+var reportReasons = []string{"spam", "harassment", "hate", "violence", "sexual", "impersonation", "other"}
+
+// MaxReportDetailsLength is plenty for a link or two and some context.
+const MaxReportDetailsLength = 1000
+
+type reportRequest struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details"`
+}
+
+type reportResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ReportedUserID uuid.UUID  `json:"reported_user_id"`
+	ChirpID        *uuid.UUID `json:"chirp_id"`
+	Reason         string     `json:"reason"`
+	Details        string     `json:"details"`
+	Status         string     `json:"status"`
+}
+
+// ReportChirp reports a chirp to the moderators.
+func ReportChirp(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+		if err != nil {
+			logging.FromContext(r.Context()).Info("invalid chirp ID", "err", err)
+			problem.Error(w, r, http.StatusBadRequest, problem.InvalidID, "Chirp ID must be a UUID.")
+			return
+		}
+
+		var req reportRequest
+		if !validate.DecodeJSON(w, r, &req) || !validReport(w, r, req) {
+			return
+		}
+
+		// only chirps people can see can be reported. blocks don't count
+		// here: being blocked by someone who harasses you shouldn't keep you
+		// from reporting them.
+		row, err := cfg.DB.GetChirpWithAuthor(r.Context(), database.GetChirpWithAuthorParams{
+			ID: chirpID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logging.FromContext(r.Context()).Info("chirp not found", "err", err)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Chirp not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("database error", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		createReport(cfg, w, r, req, database.CreateReportParams{
+			ReporterID:     userID,
+			ReportedUserID: row.Chirp.UserID,
+			ChirpID:        uuid.NullUUID{UUID: chirpID, Valid: true},
+		})
+	})
+}
+
+// ReportUser reports an account, e.g. for impersonation, to the
+// moderators.
+func ReportUser(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		var req reportRequest
+		if !validate.DecodeJSON(w, r, &req) || !validReport(w, r, req) {
+			return
+		}
+
+		// blocks don't count here either, see ReportChirp.
+		row, err := cfg.DB.GetProfileByHandle(r.Context(), database.GetProfileByHandleParams{
+			Handle: r.PathValue("handle"),
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logging.FromContext(r.Context()).Info("profile not found", "handle", r.PathValue("handle"))
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "User not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("database error", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		createReport(cfg, w, r, req, database.CreateReportParams{
+			ReporterID:     userID,
+			ReportedUserID: row.User.ID,
+		})
+	})
+}
+
+func validReport(w http.ResponseWriter, r *http.Request, req reportRequest) bool {
+	var v validate.Validator
+	v.Required("reason", req.Reason)
+	v.Check(slices.Contains(reportReasons, req.Reason), "reason", "invalid", "must be one of "+strings.Join(reportReasons, ", "))
+	v.MaxLength("details", req.Details, MaxReportDetailsLength)
+	if !v.Valid() {
+		logging.FromContext(r.Context()).Info("invalid report", "errors", v.Errors)
+		v.Write(w, r)
+		return false
+	}
+	return true
+}
+
+// createReport stores the report once the handlers know what it's about.
+func createReport(cfg *chirpy.ApiConfig, w http.ResponseWriter, r *http.Request, req reportRequest, params database.CreateReportParams) {
+	if params.ReporterID == params.ReportedUserID {
+		logging.FromContext(r.Context()).Info("self report")
+		problem.Error(w, r, http.StatusBadRequest, problem.ValidationFailed, "You can't report yourself.")
+		return
+	}
+
+	params.Reason = req.Reason
+	params.Details = strings.TrimSpace(req.Details)
+	report, err := cfg.DB.CreateReport(r.Context(), params)
+	if err != nil {
+		if database.IsUniqueViolation(err, database.ReportsOpenChirpKey) || database.IsUniqueViolation(err, database.ReportsOpenUserKey) {
+			logging.FromContext(r.Context()).Info("duplicate report")
+			problem.Error(w, r, http.StatusConflict, problem.Conflict, "You already reported this and it hasn't been resolved yet.")
+			return
+		}
+		logging.FromContext(r.Context()).Error("failed to create report", "err", err)
+		problem.InternalError(w, r)
+		return
+	}
+	logging.FromContext(r.Context()).Info("report created", "report_id", report.ID, "reason", report.Reason)
+
+	res := reportResponse{
+		ID:             report.ID,
+		CreatedAt:      report.CreatedAt,
+		ReportedUserID: report.ReportedUserID,
+		Reason:         report.Reason,
+		Details:        report.Details,
+		Status:         report.Status,
+	}
+	if report.ChirpID.Valid {
+		res.ChirpID = &report.ChirpID.UUID
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+	}
+}
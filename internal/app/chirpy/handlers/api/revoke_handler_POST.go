@@ -3,13 +3,14 @@ package api
 import (
 	"database/sql"
 	"errors"
-	"log"
 	"net/http"
 	"time"
 
 	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
 	"github.com/johndosdos/chirpy/internal/auth"
 	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
 )
 
 func Revoke(cfg *chirpy.ApiConfig) http.Handler {
@@ -17,8 +18,8 @@ func Revoke(cfg *chirpy.ApiConfig) http.Handler {
 		// get refresh token from client request
 		tokenString, err := auth.GetBearerToken(r.Header)
 		if err != nil {
-			log.Println("failed to extract Bearer token: ", err)
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			logging.FromContext(r.Context()).Warn("failed to extract Bearer token", "err", err)
+			problem.Error(w, r, http.StatusUnauthorized, problem.Unauthorized, "Missing or invalid refresh token.")
 			return
 		}
 
@@ -27,14 +28,15 @@ func Revoke(cfg *chirpy.ApiConfig) http.Handler {
 		user, err := cfg.DB.GetUserFromRefreshToken(r.Context(), tokenString)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
-				log.Println("user not found: ", err)
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				logging.FromContext(r.Context()).Warn("user not found", "err", err)
+				problem.Error(w, r, http.StatusUnauthorized, problem.Unauthorized, "Missing or invalid refresh token.")
 			} else {
-				log.Println("failed to get refresh token user: ", err)
-				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				logging.FromContext(r.Context()).Error("failed to get refresh token user", "err", err)
+				problem.InternalError(w, r)
 			}
 			return
 		}
+		logging.SetUserID(r.Context(), user.UserID)
 
 		err = cfg.DB.UpdateRefreshToken(r.Context(), database.UpdateRefreshTokenParams{
 			RevokedAt: sql.NullTime{
@@ -45,8 +47,8 @@ func Revoke(cfg *chirpy.ApiConfig) http.Handler {
 			UserID:    user.UserID,
 		})
 		if err != nil {
-			log.Println("failed to update user refresh token: ", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("failed to update user refresh token", "err", err)
+			problem.InternalError(w, r)
 			return
 		}
 
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// CancelScheduledChirp deletes one of the user's scheduled chirps before it
+// goes out. its media can be attached to another chirp afterwards.
+func CancelScheduledChirp(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+		if err != nil {
+			logging.FromContext(r.Context()).Info("invalid chirp ID", "err", err)
+			problem.Error(w, r, http.StatusBadRequest, problem.InvalidID, "Chirp ID must be a UUID.")
+			return
+		}
+
+		n, err := cfg.DB.CancelScheduledChirp(r.Context(), database.CancelScheduledChirpParams{
+			ID:     chirpID,
+			UserID: userID,
+		})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to cancel scheduled chirp", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		if n == 0 {
+			logging.FromContext(r.Context()).Info("scheduled chirp not found", "chirp_id", chirpID)
+			problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Scheduled chirp not found.")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// GetScheduledChirps lists the user's chirps that are yet to be published,
+// next to go out first.
+func GetScheduledChirps(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		user, err := cfg.DB.GetUserByID(r.Context(), userID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get user", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		rows, err := cfg.DB.GetScheduledChirps(r.Context(), userID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get scheduled chirps", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		chirps := make([]chirpResponse, len(rows))
+		for i, row := range rows {
+			chirps[i] = ownChirpResponse(user, row)
+		}
+		if err := withMedia(r.Context(), cfg.DB, chirps); err != nil {
+			logging.FromContext(r.Context()).Error("failed to get chirp media", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		if err := withQuotes(r.Context(), cfg.DB, uuid.NullUUID{UUID: userID, Valid: true}, chirps); err != nil {
+			logging.FromContext(r.Context()).Error("failed to get quotes", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(chirps); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+		}
+	})
+}
+
+// ownChirpResponse is newChirpResponse for a chirp of user, who we already
+// have, e.g. one that isn't public yet.
+func ownChirpResponse(user database.User, chirp database.Chirp) chirpResponse {
+	return newChirpResponse(database.GetChirpWithAuthorRow{
+		Chirp:             chirp,
+		AuthorHandle:      user.Handle,
+		AuthorDisplayName: user.DisplayName,
+		AuthorAvatarUrl:   user.AvatarUrl,
+	})
+}
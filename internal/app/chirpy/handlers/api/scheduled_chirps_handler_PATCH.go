@@ -0,0 +1,95 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// RescheduleChirp moves the publish_at of one of the user's scheduled
+// chirps. chirps that were already published are not found.
+func RescheduleChirp(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type request struct {
+			PublishAt *time.Time `json:"publish_at"`
+		}
+
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+		if err != nil {
+			logging.FromContext(r.Context()).Info("invalid chirp ID", "err", err)
+			problem.Error(w, r, http.StatusBadRequest, problem.InvalidID, "Chirp ID must be a UUID.")
+			return
+		}
+
+		var req request
+		if !validate.DecodeJSON(w, r, &req) {
+			return
+		}
+
+		var v validate.Validator
+		v.Check(req.PublishAt != nil, "publish_at", "required", "is required")
+		if req.PublishAt != nil {
+			validatePublishAt(&v, *req.PublishAt)
+		}
+		if !v.Valid() {
+			logging.FromContext(r.Context()).Info("invalid publish time", "errors", v.Errors)
+			v.Write(w, r)
+			return
+		}
+
+		chirp, err := cfg.DB.RescheduleChirp(r.Context(), database.RescheduleChirpParams{
+			ID:        chirpID,
+			UserID:    userID,
+			PublishAt: sql.NullTime{Time: *req.PublishAt, Valid: true},
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logging.FromContext(r.Context()).Info("scheduled chirp not found", "chirp_id", chirpID)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "Scheduled chirp not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("failed to reschedule chirp", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		user, err := cfg.DB.GetUserByID(r.Context(), userID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get user", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		res := []chirpResponse{ownChirpResponse(user, chirp)}
+		if err := withMedia(r.Context(), cfg.DB, res); err != nil {
+			logging.FromContext(r.Context()).Error("failed to get chirp media", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+		if err := withQuotes(r.Context(), cfg.DB, uuid.NullUUID{UUID: userID, Valid: true}, res); err != nil {
+			logging.FromContext(r.Context()).Error("failed to get quotes", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(res[0]); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response json", "err", err)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// GetProfile returns the public profile of the user with the given handle,
+// in any case. it doesn't need authentication and never includes the
+// email.
+func GetProfile(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type response struct {
+			ID          uuid.UUID `json:"id"`
+			CreatedAt   time.Time `json:"created_at"`
+			Handle      string    `json:"handle"`
+			DisplayName string    `json:"display_name"`
+			Bio         string    `json:"bio"`
+			Location    string    `json:"location"`
+			Website     string    `json:"website"`
+			AvatarURL   string    `json:"avatar_url"`
+			IsChirpyRed bool      `json:"is_chirpy_red"`
+			ChirpCount  int64     `json:"chirp_count"`
+		}
+
+		viewerID, ok := viewer(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		// users on either side of a block don't see each other.
+		row, err := cfg.DB.GetProfileByHandle(r.Context(), database.GetProfileByHandleParams{
+			Handle:   r.PathValue("handle"),
+			ViewerID: viewerID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logging.FromContext(r.Context()).Info("profile not found", "handle", r.PathValue("handle"))
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "User not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("database error", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(response{
+			ID:          row.User.ID,
+			CreatedAt:   row.User.CreatedAt,
+			Handle:      row.User.Handle,
+			DisplayName: row.User.DisplayName,
+			Bio:         row.User.Bio,
+			Location:    row.User.Location,
+			Website:     row.User.Website,
+			AvatarURL:   row.User.AvatarUrl,
+			IsChirpyRed: row.User.IsChirpyRed,
+			ChirpCount:  row.ChirpCount,
+		})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response", "err", err)
+		}
+	})
+}
@@ -2,22 +2,27 @@ package api
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
 	"github.com/johndosdos/chirpy/internal/auth"
 	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
 )
 
 // CreateUser expects an email json field from the http request.
 func CreateUser(cfg *chirpy.ApiConfig) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		type request struct {
-			Email    string `json:"email"`
-			Password string `json:"password"`
+			Email       string `json:"email"`
+			Password    string `json:"password"`
+			Handle      string `json:"handle"`
+			DisplayName string `json:"display_name"`
 		}
 
 		type response struct {
@@ -26,40 +31,83 @@ func CreateUser(cfg *chirpy.ApiConfig) http.Handler {
 			UpdatedAt   time.Time `json:"updated_at"`
 			Email       string    `json:"email"`
 			IsChirpyRed bool      `json:"is_chirpy_red"`
+			Handle      string    `json:"handle"`
+			DisplayName string    `json:"display_name"`
 		}
 
 		var req request
 
 		// parse and decode request.
 
-		// error returned from decoding is usually caused
-		// by the client side, so we return http error 400
-		// (bad request).
-		decoder := json.NewDecoder(r.Body)
-		err := decoder.Decode(&req)
-		if err != nil {
-			log.Println("Invalid JSON request: ", err)
-			http.Error(w, "Bad client request", http.StatusBadRequest)
+		// errors here are caused by the client side, so DecodeJSON
+		// responds with http error 400 (bad request) itself.
+		if !validate.DecodeJSON(w, r, &req) {
+			return
+		}
+
+		var v validate.Validator
+		v.Required("email", req.Email)
+		v.Email("email", req.Email)
+		v.Required("password", req.Password)
+		v.Password("password", req.Password)
+		v.Handle("handle", req.Handle)
+		v.MaxLength("display_name", req.DisplayName, validate.MaxDisplayNameLength)
+		if !v.Valid() {
+			logging.FromContext(r.Context()).Info("invalid request", "errors", v.Errors)
+			v.Write(w, r)
 			return
 		}
 
 		// hash user password before storing to database
-		hashedPw, err := auth.HashPassword(req.Password)
+		hashedPw, err := auth.HashPassword(r.Context(), req.Password)
 		if err != nil {
-			log.Println("Failed to hash password: ", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("failed to hash password", "err", err)
+			problem.InternalError(w, r)
 			return
 		}
 
-		// return http error 500 since the error is usually caused
-		// by the server.
-		user, err := cfg.DB.CreateUser(r.Context(), database.CreateUserParams{
-			Email:          req.Email,
-			HashedPassword: hashedPw,
-		})
+		// the handle is optional at sign up. a generated one that's
+		// already taken is our problem, not the client's, so try another.
+		generated := req.Handle == ""
+
+		var user database.User
+		for attempt := 1; ; attempt++ {
+			if generated {
+				req.Handle, err = chirpy.DefaultHandle()
+				if err != nil {
+					logging.FromContext(r.Context()).Error("failed to generate handle", "err", err)
+					problem.InternalError(w, r)
+					return
+				}
+			}
+
+			// return http error 500 since the error is usually caused
+			// by the server.
+			user, err = cfg.DB.CreateUser(r.Context(), database.CreateUserParams{
+				Email:          req.Email,
+				HashedPassword: hashedPw,
+				Handle:         req.Handle,
+				DisplayName:    strings.TrimSpace(req.DisplayName),
+			})
+			if generated && attempt < chirpy.DefaultHandleAttempts && database.IsUniqueViolation(err, database.UsersHandleKey) {
+				logging.FromContext(r.Context()).Warn("generated handle already taken", "attempt", attempt)
+				continue
+			}
+			break
+		}
+		if database.IsUniqueViolation(err, database.UsersEmailKey) {
+			logging.FromContext(r.Context()).Info("email already registered")
+			emailTaken(w, r)
+			return
+		}
+		if database.IsUniqueViolation(err, database.UsersHandleKey) && !generated {
+			logging.FromContext(r.Context()).Info("handle already taken")
+			handleTaken(w, r)
+			return
+		}
 		if err != nil {
-			log.Println("Unexpected error: ", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("failed to create user", "err", err)
+			problem.InternalError(w, r)
 			return
 		}
 
@@ -77,11 +125,28 @@ func CreateUser(cfg *chirpy.ApiConfig) http.Handler {
 			UpdatedAt:   user.UpdatedAt,
 			Email:       user.Email,
 			IsChirpyRed: user.IsChirpyRed,
+			Handle:      user.Handle,
+			DisplayName: user.DisplayName,
 		})
 		if err != nil {
-			log.Println("failed to encode response: ", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("failed to encode response", "err", err)
 			return
 		}
 	})
 }
+
+// emailTaken is the 409 for creating or updating a user with an email that
+// another user already has.
+func emailTaken(w http.ResponseWriter, r *http.Request) {
+	p := problem.New(http.StatusConflict, problem.Conflict, "That email is already registered.")
+	p.Errors = []problem.FieldError{{Field: "email", Code: "taken", Message: "is already registered"}}
+	p.Write(w, r)
+}
+
+// handleTaken is the 409 for a handle that another user already has, in
+// any case.
+func handleTaken(w http.ResponseWriter, r *http.Request) {
+	p := problem.New(http.StatusConflict, problem.Conflict, "That handle is already taken.")
+	p.Errors = []problem.FieldError{{Field: "handle", Code: "taken", Message: "is already taken"}}
+	p.Write(w, r)
+}
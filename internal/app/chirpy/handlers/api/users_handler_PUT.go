@@ -2,14 +2,16 @@ package api
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
 	"github.com/johndosdos/chirpy/internal/auth"
 	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
 )
 
 func UpdateUserInfo(cfg *chirpy.ApiConfig) http.Handler {
@@ -29,36 +31,36 @@ func UpdateUserInfo(cfg *chirpy.ApiConfig) http.Handler {
 
 		var req request
 
-		// retrieve user token from authorization header and
-		// then we validate it
-		tokenString, err := auth.GetBearerToken(r.Header)
-		if err != nil {
-			log.Println("invalid authorization header: ", err)
-			http.Error(w, "Bad request", http.StatusBadRequest)
-			return
-		}
-
-		userID, err := auth.ValidateJWT(tokenString, cfg.Secret)
-		if err != nil {
-			log.Println("invalid access token: ", err)
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		// authenticate the user
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
 			return
 		}
 
 		// decode client request; email and password in this case
 		//
 		// and then we hash user password and update user's database entry
-		err = json.NewDecoder(r.Body).Decode(&req)
-		if err != nil {
-			log.Println("failed to decode request body: ", err)
-			http.Error(w, "Bad request", http.StatusBadRequest)
+		//
+		// PUT replaces both, so both are required.
+		if !validate.DecodeJSON(w, r, &req) {
+			return
+		}
+
+		var v validate.Validator
+		v.Required("email", req.Email)
+		v.Email("email", req.Email)
+		v.Required("password", req.Password)
+		v.Password("password", req.Password)
+		if !v.Valid() {
+			logging.FromContext(r.Context()).Info("invalid request", "errors", v.Errors)
+			v.Write(w, r)
 			return
 		}
 
-		hashedPassword, err := auth.HashPassword(req.Password)
+		hashedPassword, err := auth.HashPassword(r.Context(), req.Password)
 		if err != nil {
-			log.Println("failed to hash user password: ", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("failed to hash user password", "err", err)
+			problem.InternalError(w, r)
 			return
 		}
 
@@ -67,9 +69,14 @@ func UpdateUserInfo(cfg *chirpy.ApiConfig) http.Handler {
 			HashedPassword: hashedPassword,
 			ID:             userID,
 		})
+		if database.IsUniqueViolation(err, database.UsersEmailKey) {
+			logging.FromContext(r.Context()).Info("email already registered")
+			emailTaken(w, r)
+			return
+		}
 		if err != nil {
-			log.Println("failed to update user info in the database: ", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("failed to update user info in the database", "err", err)
+			problem.InternalError(w, r)
 			return
 		}
 
@@ -85,8 +92,7 @@ func UpdateUserInfo(cfg *chirpy.ApiConfig) http.Handler {
 			IsChirpyRed: user.IsChirpyRed,
 		})
 		if err != nil {
-			log.Println("failed to encode server response: ", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logging.FromContext(r.Context()).Error("failed to encode server response", "err", err)
 			return
 		}
 	})
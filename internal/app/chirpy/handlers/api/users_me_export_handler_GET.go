@@ -0,0 +1,140 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// ExportMe sends the authenticated user's profile, the ID, timestamps and
+// body of each of their chirps, and their sessions as a JSON file download.
+// that's all it has: drafts, bookmarks, muted words, blocks and mutes,
+// media, reports and edit history aren't in it. password hashes and
+// refresh token values are credentials, not data, and are left out too.
+func ExportMe(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type profile struct {
+			ID          uuid.UUID  `json:"id"`
+			CreatedAt   time.Time  `json:"created_at"`
+			UpdatedAt   time.Time  `json:"updated_at"`
+			Email       string     `json:"email"`
+			Handle      string     `json:"handle"`
+			DisplayName string     `json:"display_name"`
+			Bio         string     `json:"bio"`
+			Location    string     `json:"location"`
+			Website     string     `json:"website"`
+			AvatarURL   string     `json:"avatar_url"`
+			IsChirpyRed bool       `json:"is_chirpy_red"`
+			Role        string     `json:"role"`
+			DeleteAfter *time.Time `json:"delete_after"`
+		}
+
+		type chirp struct {
+			ID        uuid.UUID `json:"id"`
+			CreatedAt time.Time `json:"created_at"`
+			UpdatedAt time.Time `json:"updated_at"`
+			Body      string    `json:"body"`
+		}
+
+		type session struct {
+			CreatedAt time.Time  `json:"created_at"`
+			ExpiresAt time.Time  `json:"expires_at"`
+			RevokedAt *time.Time `json:"revoked_at"`
+		}
+
+		type export struct {
+			ExportedAt time.Time `json:"exported_at"`
+			Profile    profile   `json:"profile"`
+			Chirps     []chirp   `json:"chirps"`
+			Sessions   []session `json:"sessions"`
+		}
+
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		user, err := cfg.DB.GetUserByID(r.Context(), userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logging.FromContext(r.Context()).Info("user not found", "err", err)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "User not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("database error", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		chirps, err := cfg.DB.GetChirpsByUser(r.Context(), userID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get chirps", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		tokens, err := cfg.DB.GetRefreshTokensByUser(r.Context(), userID)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to get refresh tokens", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		data := export{
+			ExportedAt: time.Now().UTC(),
+			Profile: profile{
+				ID:          user.ID,
+				CreatedAt:   user.CreatedAt,
+				UpdatedAt:   user.UpdatedAt,
+				Email:       user.Email,
+				Handle:      user.Handle,
+				DisplayName: user.DisplayName,
+				Bio:         user.Bio,
+				Location:    user.Location,
+				Website:     user.Website,
+				AvatarURL:   user.AvatarUrl,
+				IsChirpyRed: user.IsChirpyRed,
+				Role:        user.Role,
+				DeleteAfter: nullTime(user.DeleteAfter),
+			},
+			Chirps:   make([]chirp, 0, len(chirps)),
+			Sessions: make([]session, 0, len(tokens)),
+		}
+		for _, c := range chirps {
+			data.Chirps = append(data.Chirps, chirp{ID: c.ID, CreatedAt: c.CreatedAt, UpdatedAt: c.UpdatedAt, Body: c.Body})
+		}
+		for _, t := range tokens {
+			data.Sessions = append(data.Sessions, session{CreatedAt: t.CreatedAt, ExpiresAt: t.ExpiresAt, RevokedAt: nullTime(t.RevokedAt)})
+		}
+
+		filename := fmt.Sprintf("chirpy-%s-%s.json", user.Handle, data.ExportedAt.Format("2006-01-02"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(data); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode export", "err", err)
+		}
+	})
+}
+
+// nullTime turns a nullable column into a pointer, which encodes as null
+// instead of sql.NullTime's {"Time": ..., "Valid": ...}.
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// DeleteMe schedules the authenticated user's account for deletion after
+// cfg.DeletionGracePeriod. every refresh token is revoked right away, and
+// the profile and chirps are hidden. logging in before the grace period is
+// over restores the account; after it, chirpy.PurgeDeletedUsers deletes it
+// for good.
+func DeleteMe(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type request struct {
+			CurrentPassword string `json:"current_password"`
+		}
+
+		type response struct {
+			DeleteAfter time.Time `json:"delete_after"`
+		}
+
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		// the password confirms it's really the user, not someone with
+		// their access token.
+		var req request
+		if !validate.DecodeJSON(w, r, &req) {
+			return
+		}
+
+		var v validate.Validator
+		v.Required("current_password", req.CurrentPassword)
+		if !v.Valid() {
+			logging.FromContext(r.Context()).Info("invalid request", "errors", v.Errors)
+			v.Write(w, r)
+			return
+		}
+
+		user, err := cfg.DB.GetUserByID(r.Context(), userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logging.FromContext(r.Context()).Info("user not found", "err", err)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "User not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("database error", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		if !checkCurrentPassword(w, r, req.CurrentPassword, user) {
+			return
+		}
+
+		// without a grace period there's nothing to wait for.
+		if cfg.DeletionGracePeriod == 0 {
+			if err := cfg.DB.DeleteUser(r.Context(), userID); err != nil {
+				logging.FromContext(r.Context()).Error("failed to delete user", "err", err)
+				problem.InternalError(w, r)
+				return
+			}
+
+			logging.FromContext(r.Context()).Info("user deleted")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		deleteAfter := time.Now().UTC().Add(cfg.DeletionGracePeriod)
+		err = cfg.InTx(r.Context(), func(q *database.Queries) error {
+			if _, err := q.ScheduleUserDeletion(r.Context(), database.ScheduleUserDeletionParams{
+				DeleteAfter: sql.NullTime{Time: deleteAfter, Valid: true},
+				ID:          userID,
+			}); err != nil {
+				return err
+			}
+
+			_, err := q.RevokeUserRefreshTokens(r.Context(), userID)
+			return err
+		})
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to schedule user deletion", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		logging.FromContext(r.Context()).Info("user deletion scheduled", "delete_after", deleteAfter)
+
+		// 202: the deletion happens later, and can still be undone.
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+
+		if err := json.NewEncoder(w).Encode(response{DeleteAfter: deleteAfter}); err != nil {
+			logging.FromContext(r.Context()).Error("failed to encode response", "err", err)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// GetMe returns the authenticated user. the ETag header is what PATCH
+// /api/users/me expects in If-Match.
+func GetMe(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		user, err := cfg.DB.GetUserByID(r.Context(), userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				// the token outlived the account.
+				logging.FromContext(r.Context()).Info("user not found", "err", err)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "User not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("database error", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		writeMe(w, r, user)
+	})
+}
+
+// writeMe sends the private view of a user, which unlike public profiles
+// includes the email.
+func writeMe(w http.ResponseWriter, r *http.Request, user database.User) {
+	type response struct {
+		Id          uuid.UUID `json:"id"`
+		CreatedAt   time.Time `json:"created_at"`
+		UpdatedAt   time.Time `json:"updated_at"`
+		Email       string    `json:"email"`
+		IsChirpyRed bool      `json:"is_chirpy_red"`
+		Role        string    `json:"role"`
+		Handle      string    `json:"handle"`
+		DisplayName string    `json:"display_name"`
+		Bio         string    `json:"bio"`
+		Location    string    `json:"location"`
+		Website     string    `json:"website"`
+		AvatarURL   string    `json:"avatar_url"`
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", userETag(user))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	err := json.NewEncoder(w).Encode(response{
+		Id:          user.ID,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+		Email:       user.Email,
+		IsChirpyRed: user.IsChirpyRed,
+		Role:        user.Role,
+		Handle:      user.Handle,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		Location:    user.Location,
+		Website:     user.Website,
+		AvatarURL:   user.AvatarUrl,
+	})
+	if err != nil {
+		logging.FromContext(r.Context()).Error("failed to encode response", "err", err)
+	}
+}
+
+// userETag is a strong ETag derived from updated_at, which every query
+// changing a user sets, including UpgradeUser for is_chirpy_red. postgres
+// stores microseconds, so that's the precision used.
+func userETag(user database.User) string {
+	return `"` + strconv.FormatInt(user.UpdatedAt.UnixMicro(), 36) + `"`
+}
+
+// parseUserETag turns an If-Match value back into the updated_at it was made
+// from. "*" and a missing header match any version and return false.
+// anything unparseable returns the zero time, which matches nothing.
+func parseUserETag(header string) (time.Time, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return time.Time{}, false
+	}
+
+	// weak ETags never match for If-Match, and we don't hand out lists.
+	micros, err := strconv.ParseInt(strings.Trim(header, `"`), 36, 64)
+	if err != nil || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		return time.Time{}, true
+	}
+
+	return time.UnixMicro(micros), true
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+	"github.com/johndosdos/chirpy/internal/auth"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+// PatchMe updates only the fields present in the request. changing the
+// email or password needs the current password, so a stolen access token
+// isn't enough to take over the account.
+//
+// if the request has an If-Match header with the ETag from GET (or a
+// previous PATCH), the update only happens if nobody changed the user in
+// the meantime; otherwise it's a 412.
+func PatchMe(cfg *chirpy.ApiConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// pointers tell "not sent" apart from "sent empty".
+		type request struct {
+			Email           *string `json:"email"`
+			Password        *string `json:"password"`
+			CurrentPassword string  `json:"current_password"`
+
+			// profile fields don't need the current password. sending ""
+			// clears them, except for the handle, which can't be empty.
+			Handle      *string `json:"handle"`
+			DisplayName *string `json:"display_name"`
+			Bio         *string `json:"bio"`
+			Location    *string `json:"location"`
+			Website     *string `json:"website"`
+			AvatarURL   *string `json:"avatar_url"`
+		}
+
+		userID, ok := authenticate(cfg, w, r)
+		if !ok {
+			return
+		}
+
+		var req request
+		if !validate.DecodeJSON(w, r, &req) {
+			return
+		}
+
+		changesCredentials := req.Email != nil || req.Password != nil
+
+		var v validate.Validator
+		if req.Email != nil {
+			v.Required("email", *req.Email)
+			v.Email("email", *req.Email)
+		}
+		if req.Password != nil {
+			v.Required("password", *req.Password)
+			v.Password("password", *req.Password)
+		}
+		if changesCredentials {
+			v.Required("current_password", req.CurrentPassword)
+		}
+		if req.Handle != nil {
+			v.Required("handle", *req.Handle)
+			v.Handle("handle", *req.Handle)
+		}
+		if req.DisplayName != nil {
+			v.MaxLength("display_name", *req.DisplayName, validate.MaxDisplayNameLength)
+		}
+		if req.Bio != nil {
+			v.MaxLength("bio", *req.Bio, validate.MaxBioLength)
+		}
+		if req.Location != nil {
+			v.MaxLength("location", *req.Location, validate.MaxLocationLength)
+		}
+		if req.Website != nil {
+			v.URL("website", *req.Website)
+		}
+		if req.AvatarURL != nil {
+			v.URL("avatar_url", *req.AvatarURL)
+		}
+		if !v.Valid() {
+			logging.FromContext(r.Context()).Info("invalid request", "errors", v.Errors)
+			v.Write(w, r)
+			return
+		}
+
+		user, err := cfg.DB.GetUserByID(r.Context(), userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logging.FromContext(r.Context()).Info("user not found", "err", err)
+				problem.Error(w, r, http.StatusNotFound, problem.NotFound, "User not found.")
+			} else {
+				logging.FromContext(r.Context()).Error("database error", "err", err)
+				problem.InternalError(w, r)
+			}
+			return
+		}
+
+		// check the precondition before hashing anything. the update below
+		// checks it again in case the user changes in between.
+		ifUpdatedAt, conditional := parseUserETag(r.Header.Get("If-Match"))
+		if conditional && !ifUpdatedAt.Equal(user.UpdatedAt) {
+			logging.FromContext(r.Context()).Info("stale If-Match", "if_match", r.Header.Get("If-Match"))
+			preconditionFailed(w, r)
+			return
+		}
+
+		// an empty PATCH changes nothing, not even updated_at.
+		changesProfile := req.Handle != nil || req.DisplayName != nil || req.Bio != nil ||
+			req.Location != nil || req.Website != nil || req.AvatarURL != nil
+		if !changesCredentials && !changesProfile {
+			writeMe(w, r, user)
+			return
+		}
+
+		params := database.PatchUserParams{
+			ID:          userID,
+			Handle:      nullString(req.Handle),
+			DisplayName: nullString(req.DisplayName),
+			Bio:         nullString(req.Bio),
+			Location:    nullString(req.Location),
+			Website:     nullString(req.Website),
+			AvatarUrl:   nullString(req.AvatarURL),
+		}
+		if conditional {
+			params.IfUpdatedAt = sql.NullTime{Time: ifUpdatedAt, Valid: true}
+		}
+
+		if changesCredentials && !checkCurrentPassword(w, r, req.CurrentPassword, user) {
+			return
+		}
+
+		params.Email = nullString(req.Email)
+		if req.Password != nil {
+			hashedPassword, err := auth.HashPassword(r.Context(), *req.Password)
+			if err != nil {
+				logging.FromContext(r.Context()).Error("failed to hash user password", "err", err)
+				problem.InternalError(w, r)
+				return
+			}
+			params.HashedPassword = sql.NullString{String: hashedPassword, Valid: true}
+		}
+
+		user, err = cfg.DB.PatchUser(r.Context(), params)
+		if errors.Is(err, sql.ErrNoRows) {
+			logging.FromContext(r.Context()).Info("user changed during update")
+			preconditionFailed(w, r)
+			return
+		}
+		if database.IsUniqueViolation(err, database.UsersEmailKey) {
+			logging.FromContext(r.Context()).Info("email already registered")
+			emailTaken(w, r)
+			return
+		}
+		if database.IsUniqueViolation(err, database.UsersHandleKey) {
+			logging.FromContext(r.Context()).Info("handle already taken")
+			handleTaken(w, r)
+			return
+		}
+		if err != nil {
+			logging.FromContext(r.Context()).Error("failed to update user", "err", err)
+			problem.InternalError(w, r)
+			return
+		}
+
+		writeMe(w, r, user)
+	})
+}
+
+// checkCurrentPassword writes a 403 and returns false if password isn't
+// the user's current password.
+func checkCurrentPassword(w http.ResponseWriter, r *http.Request, password string, user database.User) bool {
+	if err := auth.CheckPasswordHash(r.Context(), password, user.HashedPassword); err != nil {
+		logging.FromContext(r.Context()).Warn("current password mismatch", "err", err)
+		p := problem.New(http.StatusForbidden, problem.InvalidCredentials, "The current password is incorrect.")
+		p.Errors = []problem.FieldError{{Field: "current_password", Code: "incorrect", Message: "is incorrect"}}
+		p.Write(w, r)
+		return false
+	}
+
+	return true
+}
+
+// nullString turns an optional request field into a query argument; nil
+// leaves the column alone.
+func nullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: strings.TrimSpace(*s), Valid: true}
+}
+
+func preconditionFailed(w http.ResponseWriter, r *http.Request) {
+	problem.Error(w, r, http.StatusPreconditionFailed, problem.PreconditionFailed, "The resource changed since you last fetched it. Fetch it again and retry.")
+}
@@ -0,0 +1,22 @@
+package chirpy
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// DefaultHandleAttempts is how many generated handles to try when the
+// previous one was taken. with 48 random bits, running out means something
+// other than bad luck is going on.
+const DefaultHandleAttempts = 3
+
+// DefaultHandle is the handle of users who didn't pick one when signing up.
+// they can change it later with PATCH /api/users/me.
+func DefaultHandle() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate handle: %w", err)
+	}
+	return "user_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,28 @@
+package chirpy
+
+import (
+	"testing"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+)
+
+func TestDefaultHandle(t *testing.T) {
+	seen := map[string]bool{}
+	for range 100 {
+		handle, err := DefaultHandle()
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		// generated handles have to pass the same rules as picked ones.
+		var v validate.Validator
+		v.Handle("handle", handle)
+		if !v.Valid() {
+			t.Errorf("%s: %+v\n", handle, v.Errors)
+		}
+		if seen[handle] {
+			t.Errorf("%s generated twice\n", handle)
+		}
+		seen[handle] = true
+	}
+}
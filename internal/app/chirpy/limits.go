@@ -0,0 +1,28 @@
+package chirpy
+
+import (
+	"time"
+
+	"github.com/johndosdos/chirpy/internal/database"
+)
+
+// ChirpLimits are the length limits of chirp bodies, counted with
+// validate.ChirpLength.
+type ChirpLimits struct {
+	MaxLength    int
+	MaxLengthRed int
+	URLLength    int
+
+	// EditWindow is how long after posting a chirp can be edited. zero
+	// disables editing.
+	EditWindow time.Duration
+}
+
+// MaxLengthFor is how long user's chirps can be. Chirpy Red members get
+// more room.
+func (l ChirpLimits) MaxLengthFor(user database.User) int {
+	if user.IsChirpyRed {
+		return l.MaxLengthRed
+	}
+	return l.MaxLength
+}
@@ -0,0 +1,86 @@
+package chirpy
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/storage"
+)
+
+const (
+	// MediaCleanupInterval is how often abandoned uploads and the blobs of
+	// deleted media are cleaned up.
+	MediaCleanupInterval = 10 * time.Minute
+
+	// AbandonedMediaAge is how long an upload can sit unattached (and not
+	// in a draft) before it's deleted. long enough to finish writing the
+	// chirp it was uploaded for.
+	AbandonedMediaAge = 24 * time.Hour
+
+	blobDeletionBatchSize = 100
+)
+
+// CleanUpMedia deletes abandoned uploads, then the blobs of every media row
+// deleted since the last run, whether by this, by purging an account or by
+// a cascade, now and then every MediaCleanupInterval, until ctx is done.
+// run it with Workers.Go.
+//
+// running it on several replicas is fine; deleting a blob twice is not an
+// error.
+func CleanUpMedia(db *database.Queries, store storage.Store, logger *slog.Logger) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		ticker := time.NewTicker(MediaCleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			abandoned, err := db.DeleteAbandonedMedia(ctx, time.Now().Add(-AbandonedMediaAge))
+			if err != nil && ctx.Err() == nil {
+				logger.Error("failed to delete abandoned media", "err", err)
+			} else if abandoned > 0 {
+				logger.Info("deleted abandoned media", "count", abandoned)
+			}
+
+			deleted, err := deleteMediaBlobs(ctx, db, store)
+			if err != nil && ctx.Err() == nil {
+				logger.Error("failed to delete media blobs", "err", err)
+			}
+			if deleted > 0 {
+				logger.Info("deleted media blobs", "count", deleted)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+}
+
+// deleteMediaBlobs works through the queue of blobs to delete. a key stays
+// queued until its blob is gone, so a failing store only delays it.
+func deleteMediaBlobs(ctx context.Context, db *database.Queries, store storage.Store) (int, error) {
+	deleted := 0
+	for {
+		keys, err := db.GetMediaBlobDeletions(ctx, blobDeletionBatchSize)
+		if err != nil {
+			return deleted, err
+		}
+
+		for _, key := range keys {
+			if err := store.Delete(ctx, key); err != nil {
+				return deleted, err
+			}
+			if err := db.DeleteMediaBlobDeletion(ctx, key); err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+
+		if len(keys) < blobDeletionBatchSize {
+			return deleted, nil
+		}
+	}
+}
@@ -1,18 +1,51 @@
 package chirpy
 
 import (
+	"database/sql"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"sync/atomic"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/logging"
+	"github.com/johndosdos/chirpy/internal/metrics"
+	"github.com/johndosdos/chirpy/internal/storage"
+	"github.com/johndosdos/chirpy/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ApiConfig struct {
 	FileserverHits atomic.Int32
 	DB             *database.Queries
+	Conn           *sql.DB
+	QueryHooks     []database.QueryHook
 	Platform       string
 	Secret         string
 	PolkaKey       string
+	Workers        *Workers
+
+	// DeletionGracePeriod is how long after DELETE /api/users/me an
+	// account can still be restored by logging in.
+	DeletionGracePeriod time.Duration
+
+	// ChirpLimits are the length limits of chirp bodies.
+	ChirpLimits ChirpLimits
+
+	// Media stores uploaded images and their thumbnails. MediaMaxBytes caps
+	// the size of a single upload.
+	Media         storage.Store
+	MediaMaxBytes int64
+
+	// Ready is false while the server is starting up and once it starts
+	// draining on shutdown, so load balancers stop sending traffic.
+	Ready atomic.Bool
 }
 
 // incerment fileserverHits counter everytime a client visits the server,
@@ -20,6 +53,150 @@ type ApiConfig struct {
 func (cfg *ApiConfig) MiddlewareMetricsInc(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		cfg.FileserverHits.Add(1)
+		metrics.FileserverHits.Inc()
 		next.ServeHTTP(w, r)
 	})
 }
+
+// MiddlewareInstrument records request counts, latency and in-flight
+// requests for every route registered on mux. it should wrap the mux itself
+// so that unmatched requests (404s, 405s) are counted too.
+func MiddlewareInstrument(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// look up the route pattern up front; the mux only sets
+		// r.Pattern once it dispatches, but the in-flight gauge needs
+		// it before that.
+		_, pattern := mux.Handler(r)
+		route := routeLabel(pattern)
+
+		inFlight := metrics.HTTPInFlight.WithLabelValues(route)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		mux.ServeHTTP(rec, r)
+
+		status := strconv.Itoa(rec.Status())
+		metrics.HTTPRequests.WithLabelValues(route, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(route, status).Observe(time.Since(start).Seconds())
+	})
+}
+
+// MiddlewareTracing starts a server span for every request, continuing the
+// trace from the W3C traceparent header if the client sent one. spans are
+// named after the route pattern registered on mux, e.g.
+// "GET /api/chirps/{chirpID}".
+func MiddlewareTracing(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		_, pattern := mux.Handler(r)
+		ctx, span := tracing.Tracer().Start(ctx, routeLabel(pattern),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.HTTPRoute(pattern),
+				semconv.URLPath(r.URL.Path),
+				semconv.UserAgentOriginal(r.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		status := rec.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	})
+}
+
+// MiddlewareLogging assigns every request an ID, taken from the
+// X-Request-ID header when the client sent a sane one, puts a logger
+// carrying that ID into the request context and writes one access log
+// line per request.
+func MiddlewareLogging(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(logging.RequestIDHeader)
+		if !logging.ValidRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(logging.RequestIDHeader, requestID)
+
+		ctx := logging.NewContext(r.Context(), logger.With("request_id", requestID), requestID)
+		r = r.WithContext(ctx)
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		// r.Pattern is filled in by the mux while dispatching.
+		status := rec.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("route", routeLabel(r.Pattern)),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+		}
+		if userID := logging.UserID(ctx); userID != uuid.Nil {
+			attrs = append(attrs, slog.String("user_id", userID.String()))
+		}
+		if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.HasTraceID() {
+			attrs = append(attrs, slog.String("trace_id", spanCtx.TraceID().String()))
+		}
+
+		logger.LogAttrs(ctx, level, "request", append(attrs, slog.String("request_id", requestID))...)
+	})
+}
+
+func routeLabel(pattern string) string {
+	if pattern == "" {
+		return "unmatched"
+	}
+	return pattern
+}
+
+// statusRecorder remembers the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	// only the first call counts, same as net/http.
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+// Status returns the status sent to the client. handlers that never write
+// anything implicitly send 200 OK.
+func (rec *statusRecorder) Status() int {
+	if rec.status == 0 {
+		return http.StatusOK
+	}
+	return rec.status
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g.
+// to flush.
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,87 @@
+package chirpy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/johndosdos/chirpy/internal/metrics"
+)
+
+// scrape returns what /metrics would serve right now.
+func scrape(t *testing.T) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	return string(body)
+}
+
+func TestMiddlewareInstrumentLabels(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /test/instrument/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "missing" {
+			http.NotFound(w, r)
+		}
+		// otherwise nothing is written, which is an implicit 200.
+	})
+	handler := MiddlewareInstrument(mux)
+
+	for _, path := range []string{"/test/instrument/1", "/test/instrument/2", "/test/instrument/missing", "/test/no-such-route/abc123"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	body := scrape(t)
+	for _, want := range []string{
+		`chirpy_http_requests_total{route="GET /test/instrument/{id}",status="200"} 2`,
+		`chirpy_http_requests_total{route="GET /test/instrument/{id}",status="404"} 1`,
+		`chirpy_http_request_duration_seconds_count{route="GET /test/instrument/{id}",status="200"} 2`,
+		`chirpy_http_requests_in_flight{route="GET /test/instrument/{id}"} 0`,
+		`chirpy_http_requests_total{route="unmatched",status="404"}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %s in metrics\n", want)
+		}
+	}
+
+	// raw paths never become labels.
+	for _, path := range []string{"/test/instrument/1", "/test/instrument/missing", "abc123"} {
+		if strings.Contains(body, path) {
+			t.Errorf("expected no label with %s in metrics\n", path)
+		}
+	}
+}
+
+func TestStatusRecorder(t *testing.T) {
+	cases := []struct {
+		name  string
+		write func(w http.ResponseWriter)
+		want  int
+	}{
+		{"nothing written", func(w http.ResponseWriter) {}, http.StatusOK},
+		{"body only", func(w http.ResponseWriter) { w.Write([]byte("hi")) }, http.StatusOK},
+		{"explicit status", func(w http.ResponseWriter) { w.WriteHeader(http.StatusCreated) }, http.StatusCreated},
+		{"first status wins", func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusNotFound)
+			w.WriteHeader(http.StatusInternalServerError)
+		}, http.StatusNotFound},
+		{"status after body", func(w http.ResponseWriter) {
+			w.Write([]byte("hi"))
+			w.WriteHeader(http.StatusInternalServerError)
+		}, http.StatusOK},
+	}
+
+	for _, c := range cases {
+		rec := &statusRecorder{ResponseWriter: httptest.NewRecorder()}
+		c.write(rec)
+		if rec.Status() != c.want {
+			t.Errorf("%s: expected %d, got %d\n", c.name, c.want, rec.Status())
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Package problem writes error responses as RFC 7807 problem details
+// (application/problem+json), so that every error from the API has the same
+// shape:
+//
+//	{
+//	  "type": "urn:chirpy:problem:validation_failed",
+//	  "title": "Bad Request",
+//	  "status": 400,
+//	  "code": "validation_failed",
+//	  "detail": "Chirp is too long.",
+//	  "request_id": "4f0c...",
+//	  "errors": [{"field": "body", "code": "too_long", "message": "..."}]
+//	}
+//
+// clients should switch on code, which never changes, not on detail, which
+// is meant for humans and may.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+const ContentType = "application/problem+json"
+
+// Code is a stable, machine-readable error code.
+type Code string
+
+const (
+	InvalidJSON        Code = "invalid_json"
+	BodyTooLarge       Code = "body_too_large"
+	ValidationFailed   Code = "validation_failed"
+	InvalidID          Code = "invalid_id"
+	Unauthorized       Code = "unauthorized"
+	InvalidCredentials Code = "invalid_credentials"
+	AccountSuspended   Code = "account_suspended"
+	Forbidden          Code = "forbidden"
+	NotFound           Code = "not_found"
+	Conflict           Code = "conflict"
+	PreconditionFailed Code = "precondition_failed"
+	UnsupportedMedia   Code = "unsupported_media_type"
+	Internal           Code = "internal_error"
+)
+
+// FieldError points at one invalid field of the request. Code is stable
+// like Problem.Code, e.g. "required" or "too_long". Limit and Remaining are
+// only set for lengths that count differently from what a client would
+// guess, like chirp bodies; Remaining is negative when over the limit.
+type FieldError struct {
+	Field     string `json:"field"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Limit     *int   `json:"limit,omitempty"`
+	Remaining *int   `json:"remaining,omitempty"`
+}
+
+// Problem is the response body. Type is derived from Code, Title from
+// Status and RequestID from the request, so handlers only fill in the rest.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Code      Code         `json:"code"`
+	Detail    string       `json:"detail,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+func New(status int, code Code, detail string) *Problem {
+	return &Problem{
+		Type:   "urn:chirpy:problem:" + string(code),
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
+
+// Write sends p as the response. it must be called before anything else is
+// written to w.
+func (p *Problem) Write(w http.ResponseWriter, r *http.Request) {
+	p.RequestID = logging.RequestID(r.Context())
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		logging.FromContext(r.Context()).Error("failed to encode problem", "err", err)
+	}
+}
+
+// Error writes a problem without field errors, the common case.
+func Error(w http.ResponseWriter, r *http.Request, status int, code Code, detail string) {
+	New(status, code, detail).Write(w, r)
+}
+
+// Fields writes a 400 validation problem listing every invalid field.
+func Fields(w http.ResponseWriter, r *http.Request, detail string, errs ...FieldError) {
+	p := New(http.StatusBadRequest, ValidationFailed, detail)
+	p.Errors = errs
+	p.Write(w, r)
+}
+
+// InternalError hides the cause of 5xx errors from clients. handlers log the
+// actual error, and the request ID in the response lets us find it.
+func InternalError(w http.ResponseWriter, r *http.Request) {
+	Error(w, r, http.StatusInternalServerError, Internal, "Something went wrong on our end.")
+}
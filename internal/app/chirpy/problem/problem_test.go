@@ -0,0 +1,41 @@
+package problem
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/johndosdos/chirpy/internal/logging"
+)
+
+func TestFields(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/api/chirps", nil)
+	r = r.WithContext(logging.NewContext(r.Context(), logging.New(io.Discard, 0), "req-1"))
+	w := httptest.NewRecorder()
+
+	Fields(w, r, "Chirp is too long.", FieldError{Field: "body", Code: "too_long", Message: "must be at most 140 characters"})
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d\n", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("expected content type %s, got %s\n", ContentType, ct)
+	}
+
+	var p Problem
+	if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if p.Code != ValidationFailed || p.Type != "urn:chirpy:problem:validation_failed" || p.Title != "Bad Request" {
+		t.Errorf("unexpected problem: %+v\n", p)
+	}
+	if p.RequestID != "req-1" {
+		t.Errorf("expected request ID req-1, got %q\n", p.RequestID)
+	}
+	if len(p.Errors) != 1 || p.Errors[0].Field != "body" {
+		t.Errorf("expected one error for body, got %+v\n", p.Errors)
+	}
+}
@@ -0,0 +1,39 @@
+package chirpy
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/johndosdos/chirpy/internal/database"
+)
+
+// PurgeInterval is how often deleted accounts past their grace period are
+// removed. the grace period is days, so a few minutes late doesn't matter.
+const PurgeInterval = 10 * time.Minute
+
+// PurgeDeletedUsers deletes accounts whose grace period is over, now and
+// then every PurgeInterval, until ctx is done. run it with Workers.Go.
+//
+// running it on several replicas is fine; the DELETE is idempotent.
+func PurgeDeletedUsers(db *database.Queries, logger *slog.Logger) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		ticker := time.NewTicker(PurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			purged, err := db.PurgeDeletedUsers(ctx)
+			if err != nil && ctx.Err() == nil {
+				logger.Error("failed to purge deleted users", "err", err)
+			} else if purged > 0 {
+				logger.Info("purged deleted users", "count", purged)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package chirpy
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/metrics"
+)
+
+const (
+	// PublishInterval is how often due scheduled chirps are published, so
+	// a chirp goes out at most this late.
+	PublishInterval = 15 * time.Second
+
+	// publishBatchSize keeps each UPDATE short. a full batch means there may
+	// be more due, and the next one runs right away.
+	publishBatchSize = 100
+)
+
+// chirpPublisher is the part of *database.Queries PublishScheduledChirps
+// uses.
+type chirpPublisher interface {
+	PublishDueChirps(ctx context.Context, batchSize int32) ([]database.Chirp, error)
+}
+
+// PublishScheduledChirps publishes scheduled chirps whose publish_at has
+// passed, now and then every PublishInterval, until ctx is done. run it
+// with Workers.Go.
+//
+// running it on several replicas is fine; PublishDueChirps locks the rows
+// it publishes and skips the ones locked by someone else.
+func PublishScheduledChirps(db chirpPublisher, logger *slog.Logger) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		ticker := time.NewTicker(PublishInterval)
+		defer ticker.Stop()
+
+		for {
+			for {
+				published, err := db.PublishDueChirps(ctx, publishBatchSize)
+				if err != nil {
+					if ctx.Err() == nil {
+						logger.Error("failed to publish scheduled chirps", "err", err)
+					}
+					break
+				}
+				if len(published) > 0 {
+					logger.Info("published scheduled chirps", "count", len(published))
+					metrics.ChirpsCreated.Add(float64(len(published)))
+				}
+				if len(published) < publishBatchSize {
+					break
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+}
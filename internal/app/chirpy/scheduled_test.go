@@ -0,0 +1,89 @@
+package chirpy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/johndosdos/chirpy/internal/database"
+)
+
+// fakePublisher returns batches in order, then cancels the worker.
+type fakePublisher struct {
+	batches []int
+	err     error
+	cancel  context.CancelFunc
+	calls   int
+}
+
+func (f *fakePublisher) PublishDueChirps(ctx context.Context, batchSize int32) ([]database.Chirp, error) {
+	if batchSize != publishBatchSize {
+		return nil, errors.New("unexpected batch size")
+	}
+
+	f.calls++
+	if f.calls >= len(f.batches) {
+		f.cancel()
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.calls > len(f.batches) {
+		return nil, nil
+	}
+	return make([]database.Chirp, f.batches[f.calls-1]), nil
+}
+
+func runPublisher(t *testing.T, f *fakePublisher) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+	done := make(chan struct{})
+	go func() {
+		PublishScheduledChirps(f, slog.New(slog.NewTextHandler(io.Discard, nil)))(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		cancel()
+		t.Fatalf("worker didn't stop after %d calls\n", f.calls)
+	}
+}
+
+func TestPublishScheduledChirpsDrainsFullBatches(t *testing.T) {
+	cases := []struct {
+		name    string
+		batches []int
+	}{
+		{"nothing due", []int{0}},
+		{"short batch", []int{publishBatchSize - 1}},
+		{"full then short", []int{publishBatchSize, publishBatchSize, 3}},
+		{"full then empty", []int{publishBatchSize, 0}},
+	}
+
+	for _, c := range cases {
+		f := &fakePublisher{batches: c.batches}
+		runPublisher(t, f)
+
+		// full batches are followed right away, the first short one waits
+		// for the next tick, by which time the worker was cancelled.
+		if f.calls != len(c.batches) {
+			t.Errorf("%s: expected %d calls, got %d\n", c.name, len(c.batches), f.calls)
+		}
+	}
+}
+
+func TestPublishScheduledChirpsStopsOnError(t *testing.T) {
+	f := &fakePublisher{batches: []int{publishBatchSize}, err: errors.New("connection refused")}
+	runPublisher(t, f)
+
+	if f.calls != 1 {
+		t.Errorf("expected 1 call, got %d\n", f.calls)
+	}
+}
@@ -0,0 +1,24 @@
+package chirpy
+
+import (
+	"context"
+
+	"github.com/johndosdos/chirpy/internal/database"
+)
+
+// InTx runs fn in a transaction, committing if it returns nil and rolling
+// back otherwise. queries made through q go through the same hooks as
+// cfg.DB, so they show up in metrics and traces like any other.
+func (cfg *ApiConfig) InTx(ctx context.Context, fn func(q *database.Queries) error) error {
+	tx, err := cfg.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(database.New(database.Observe(tx, cfg.QueryHooks...))); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,45 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+	"github.com/rivo/uniseg"
+)
+
+// urlPattern finds links in chirps. it doesn't have to be exact: whatever
+// it matches counts as a link, the rest counts character by character.
+var urlPattern = regexp.MustCompile(`https?://[^\s]+`)
+
+// ChirpLength is how long a chirp is for its limit. it counts what people
+// see as one character (a grapheme cluster), so "é", "🇵🇭" and a family
+// emoji each count once however many code points or bytes they take.
+// every link counts as urlLength, so long links don't eat up the limit and
+// shortened ones don't buy extra room.
+func ChirpLength(body string, urlLength int) int {
+	length := 0
+	last := 0
+	for _, loc := range urlPattern.FindAllStringIndex(body, -1) {
+		length += uniseg.GraphemeClusterCount(body[last:loc[0]]) + urlLength
+		last = loc[1]
+	}
+	return length + uniseg.GraphemeClusterCount(body[last:])
+}
+
+// ChirpBody checks that body fits in limit characters as counted by
+// ChirpLength. the error says how many characters are left, which is
+// negative since the body is over the limit.
+func (v *Validator) ChirpBody(field, body string, limit, urlLength int) {
+	remaining := limit - ChirpLength(body, urlLength)
+	if remaining >= 0 {
+		return
+	}
+	v.AddError(problem.FieldError{
+		Field:     field,
+		Code:      "too_long",
+		Message:   fmt.Sprintf("must be at most %d characters, %d too many", limit, -remaining),
+		Limit:     &limit,
+		Remaining: &remaining,
+	})
+}
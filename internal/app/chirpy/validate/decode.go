@@ -0,0 +1,156 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+)
+
+// MaxBodyBytes caps JSON request bodies. the largest thing anyone sends us
+// is a chirp, so this is plenty.
+const MaxBodyBytes = 64 << 10
+
+// DecodeJSON reads a JSON object from the request body into dst, which must
+// be a pointer to a struct. fields that dst doesn't have are rejected, and
+// every unknown or mistyped field is reported, not just the first one.
+//
+// on failure it writes the problem response and returns false.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	return decode(w, r, dst, true)
+}
+
+// DecodeJSONAllowUnknown is DecodeJSON for bodies we don't control, like
+// webhooks, where the sender may add fields at any time.
+func DecodeJSONAllowUnknown(w http.ResponseWriter, r *http.Request, dst any) bool {
+	return decode(w, r, dst, false)
+}
+
+// Limit reads the ?limit= query parameter of list endpoints, between 1 and
+// max, or fallback when it's not set. on failure it writes the problem
+// response and returns false.
+func Limit(w http.ResponseWriter, r *http.Request, fallback, max int32) (int32, bool) {
+	s := r.URL.Query().Get("limit")
+	if s == "" {
+		return fallback, true
+	}
+
+	n, err := strconv.ParseInt(s, 10, 32)
+	if err != nil || n < 1 || n > int64(max) {
+		problem.Fields(w, r, "Invalid query.", problem.FieldError{
+			Field:   "limit",
+			Code:    "invalid",
+			Message: fmt.Sprintf("must be a number from 1 to %d", max),
+		})
+		return 0, false
+	}
+	return int32(n), true
+}
+
+func decode(w http.ResponseWriter, r *http.Request, dst any, strict bool) bool {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodyBytes))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			problem.Error(w, r, http.StatusRequestEntityTooLarge, problem.BodyTooLarge, fmt.Sprintf("Request body must be at most %d bytes.", MaxBodyBytes))
+		} else {
+			problem.Error(w, r, http.StatusBadRequest, problem.InvalidJSON, "Failed to read the request body.")
+		}
+		return false
+	}
+
+	// decode into raw fields first, so each field can be checked on its own.
+	// json.Unmarshal also rejects anything after the object.
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		problem.Error(w, r, http.StatusBadRequest, problem.InvalidJSON, "Request body must be a JSON object.")
+		return false
+	}
+
+	target := reflect.ValueOf(dst).Elem()
+	index := jsonFields(target.Type())
+
+	var v Validator
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		i, ok := index[name]
+		if !ok {
+			if strict {
+				v.Add(name, "unknown_field", "is not a known field")
+			}
+			continue
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(fields[name]))
+		if strict {
+			dec.DisallowUnknownFields()
+		}
+		if err := dec.Decode(target.Field(i).Addr().Interface()); err != nil {
+			v.Add(name, "invalid_type", typeMessage(err))
+		}
+	}
+
+	if !v.Valid() {
+		v.Write(w, r)
+		return false
+	}
+
+	return true
+}
+
+// jsonFields maps the JSON names of t's fields to their index. unlike
+// encoding/json, names have to match exactly.
+func jsonFields(t reflect.Type) map[string]int {
+	index := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		index[name] = i
+	}
+	return index
+}
+
+func typeMessage(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		return "is invalid"
+	}
+
+	switch typeErr.Type.Kind() {
+	case reflect.String:
+		return "must be a string"
+	case reflect.Bool:
+		return "must be a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "must be a number"
+	case reflect.Slice, reflect.Array:
+		return "must be an array"
+	default:
+		return "must be an object"
+	}
+}
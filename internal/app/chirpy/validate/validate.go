@@ -0,0 +1,164 @@
+// Package validate decodes and checks JSON request bodies. every violation
+// is collected so that clients can fix a request in one go instead of one
+// error at a time.
+//
+//	var req request
+//	if !validate.DecodeJSON(w, r, &req) {
+//		return
+//	}
+//
+//	var v validate.Validator
+//	v.Required("email", req.Email)
+//	v.Email("email", req.Email)
+//	if !v.Valid() {
+//		v.Write(w, r)
+//		return
+//	}
+package validate
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+)
+
+const (
+	// MinPasswordLength follows NIST SP 800-63B: length matters, character
+	// classes don't.
+	MinPasswordLength = 8
+
+	// MaxPasswordBytes is where bcrypt stops; anything past it is ignored
+	// (or rejected, depending on the version), so don't pretend it counts.
+	MaxPasswordBytes = 72
+
+	// MaxEmailLength is the longest address SMTP allows.
+	MaxEmailLength = 254
+)
+
+// Validator collects field errors. the zero value is ready to use.
+type Validator struct {
+	Errors []problem.FieldError
+
+	// fields that already have an error. later checks on the same field are
+	// skipped, so e.g. a missing email is reported as required and not also
+	// as invalid.
+	failed map[string]bool
+}
+
+func (v *Validator) Valid() bool {
+	return len(v.Errors) == 0
+}
+
+// Add records an error for field unless it already has one.
+func (v *Validator) Add(field, code, message string) {
+	v.AddError(problem.FieldError{Field: field, Code: code, Message: message})
+}
+
+// AddError is Add for errors with more than a code and a message.
+func (v *Validator) AddError(err problem.FieldError) {
+	if v.failed[err.Field] {
+		return
+	}
+	if v.failed == nil {
+		v.failed = make(map[string]bool)
+	}
+	v.failed[err.Field] = true
+	v.Errors = append(v.Errors, err)
+}
+
+// Check adds an error for field if ok is false.
+func (v *Validator) Check(ok bool, field, code, message string) {
+	if !ok {
+		v.Add(field, code, message)
+	}
+}
+
+func (v *Validator) Required(field, value string) {
+	v.Check(strings.TrimSpace(value) != "", field, "required", "is required")
+}
+
+// MaxLength counts characters, not bytes.
+func (v *Validator) MaxLength(field, value string, max int) {
+	v.Check(utf8.RuneCountInString(value) <= max, field, "too_long", fmt.Sprintf("must be at most %d characters", max))
+}
+
+// Email accepts a bare address like jane@example.com. display names
+// ("Jane <jane@example.com>") and addresses without a domain are rejected.
+// empty values are left to Required.
+func (v *Validator) Email(field, value string) {
+	if value == "" {
+		return
+	}
+	if len(value) > MaxEmailLength {
+		v.Add(field, "too_long", fmt.Sprintf("must be at most %d characters", MaxEmailLength))
+		return
+	}
+
+	addr, err := mail.ParseAddress(value)
+	valid := err == nil && addr.Address == value && strings.Contains(value[strings.LastIndex(value, "@"):], ".")
+	v.Check(valid, field, "invalid_email", "must be an email address like jane@example.com")
+}
+
+// Password enforces the password policy on new passwords. empty values are
+// left to Required. don't use it on login, where the policy at the time the
+// password was set is what counts.
+func (v *Validator) Password(field, value string) {
+	if value == "" {
+		return
+	}
+	v.Check(utf8.RuneCountInString(value) >= MinPasswordLength, field, "too_short", fmt.Sprintf("must be at least %d characters", MinPasswordLength))
+	v.Check(len(value) <= MaxPasswordBytes, field, "too_long", fmt.Sprintf("must be at most %d bytes", MaxPasswordBytes))
+}
+
+// Write sends every collected error as a validation problem.
+func (v *Validator) Write(w http.ResponseWriter, r *http.Request) {
+	problem.Fields(w, r, "The request has invalid fields.", v.Errors...)
+}
+
+const (
+	MaxHandleLength      = 30
+	MaxDisplayNameLength = 50
+	MaxBioLength         = 160
+	MaxLocationLength    = 30
+)
+
+var handlePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// reservedHandles would clash with routes like /api/users/me.
+var reservedHandles = map[string]bool{"me": true, "admin": true, "api": true}
+
+// Handle checks a public handle: 3 to 30 letters, digits or underscores.
+// uniqueness is case-insensitive and up to the database.
+func (v *Validator) Handle(field, value string) {
+	if value == "" {
+		return
+	}
+	if len(value) < 3 || len(value) > MaxHandleLength {
+		v.Add(field, "invalid_length", fmt.Sprintf("must be 3 to %d characters", MaxHandleLength))
+		return
+	}
+	v.Check(handlePattern.MatchString(value), field, "invalid_handle", "may only contain letters, digits and underscores")
+	v.Check(!reservedHandles[strings.ToLower(value)], field, "reserved", "is reserved")
+}
+
+// URL accepts absolute http and https URLs. empty values are left to
+// Required.
+func (v *Validator) URL(field, value string) {
+	if value == "" {
+		return
+	}
+	if len(value) > 2048 {
+		v.Add(field, "too_long", "must be at most 2048 characters")
+		return
+	}
+
+	u, err := url.Parse(value)
+	valid := err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+	v.Check(valid, field, "invalid_url", "must be an http or https URL")
+}
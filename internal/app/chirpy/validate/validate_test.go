@@ -0,0 +1,157 @@
+package validate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy/problem"
+)
+
+func TestEmail(t *testing.T) {
+	cases := map[string]bool{
+		"jane@example.com":             true,
+		"jane+chirpy@mail.example.org": true,
+		"jane":                         false,
+		"jane@localhost":               false,
+		"Jane <jane@example.com>":      false,
+		" jane@example.com":            false,
+	}
+
+	for email, want := range cases {
+		var v Validator
+		v.Email("email", email)
+		if v.Valid() != want {
+			t.Errorf("%q: expected valid=%v, got errors %+v\n", email, want, v.Errors)
+		}
+	}
+}
+
+func TestValidatorReportsEveryField(t *testing.T) {
+	var v Validator
+	v.Required("email", "")
+	v.Email("email", "")
+	v.Required("password", "short")
+	v.Password("password", "short")
+
+	if len(v.Errors) != 2 {
+		t.Fatalf("expected one error per field, got %+v\n", v.Errors)
+	}
+	if v.Errors[0].Code != "required" || v.Errors[1].Code != "too_short" {
+		t.Errorf("unexpected errors %+v\n", v.Errors)
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	type request struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+
+	decode := func(body string) (*httptest.ResponseRecorder, request) {
+		var req request
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		DecodeJSON(w, r, &req)
+		return w, req
+	}
+
+	w, req := decode(`{"email": "jane@example.com", "password": "hunter22"}`)
+	if w.Code != http.StatusOK || req.Email != "jane@example.com" {
+		t.Errorf("expected a valid body to decode, got %d %+v\n", w.Code, req)
+	}
+
+	// every problem is reported, not just the first.
+	w, _ = decode(`{"email": 42, "password": "hunter22", "admin": true, "role": "admin"}`)
+	var p problem.Problem
+	if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	if w.Code != http.StatusBadRequest || len(p.Errors) != 3 {
+		t.Errorf("expected 3 field errors, got %d %+v\n", w.Code, p.Errors)
+	}
+
+	for _, body := range []string{"", "null", "[]", `{"email": "a"} {}`} {
+		if w, _ := decode(body); w.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected 400, got %d\n", body, w.Code)
+		}
+	}
+
+	w, _ = decode(`{"email": "` + strings.Repeat("a", MaxBodyBytes) + `"}`)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 for a huge body, got %d\n", w.Code)
+	}
+}
+
+func TestHandle(t *testing.T) {
+	cases := map[string]bool{
+		"heisenberg": true,
+		"Saul_1":     true,
+		"ab":         false,
+		"me":         false,
+		"Admin":      false,
+		"no spaces":  false,
+		"émile":      false,
+	}
+
+	for handle, want := range cases {
+		var v Validator
+		v.Handle("handle", handle)
+		if v.Valid() != want {
+			t.Errorf("%q: expected valid=%v, got errors %+v\n", handle, want, v.Errors)
+		}
+	}
+}
+
+func TestChirpLength(t *testing.T) {
+	cases := map[string]int{
+		"hello":                     5,
+		"héllo":                     5,
+		"e\u0301":                   1, // e and a combining accent
+		"🇵🇭🇵🇭":                      2,
+		"👨‍👩‍👧‍👦":                   1,
+		strings.Repeat("😀", 50):     50,
+		"see https://example.com/a": 4 + 23,
+		"https://a.co https://b.co": 23 + 1 + 23,
+	}
+
+	for body, want := range cases {
+		if got := ChirpLength(body, 23); got != want {
+			t.Errorf("ChirpLength(%q) = %d, want %d\n", body, got, want)
+		}
+	}
+
+	var v Validator
+	v.ChirpBody("body", strings.Repeat("😀", 142), 140, 23)
+	if len(v.Errors) != 1 || *v.Errors[0].Remaining != -2 || *v.Errors[0].Limit != 140 {
+		t.Errorf("expected 2 too many, got %+v\n", v.Errors)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	cases := map[string]struct {
+		want int32
+		ok   bool
+	}{
+		"":           {50, true},
+		"?limit=1":   {1, true},
+		"?limit=200": {200, true},
+		"?limit=0":   {0, false},
+		"?limit=201": {0, false},
+		"?limit=ten": {0, false},
+	}
+
+	for query, c := range cases {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/"+query, nil)
+		got, ok := Limit(w, r, 50, 200)
+		if got != c.want || ok != c.ok {
+			t.Errorf("%q: expected %d, %v, got %d, %v\n", query, c.want, c.ok, got, ok)
+		}
+		if !ok && w.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected 400, got %d\n", query, w.Code)
+		}
+	}
+}
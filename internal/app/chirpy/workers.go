@@ -0,0 +1,54 @@
+package chirpy
+
+import (
+	"context"
+	"sync"
+)
+
+// Workers runs long-lived background goroutines (publishers, cleanup jobs,
+// streaming connections) and lets the server wait for them when shutting
+// down. the zero value is not usable; use NewWorkers.
+type Workers struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewWorkers() *Workers {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Workers{ctx: ctx, cancel: cancel}
+}
+
+// Go runs fn in a new goroutine. fn should return soon after ctx is done.
+func (ws *Workers) Go(fn func(ctx context.Context)) {
+	ws.wg.Add(1)
+	go func() {
+		defer ws.wg.Done()
+		fn(ws.ctx)
+	}()
+}
+
+// Done is closed once Stop has been called. handlers holding a connection
+// open (e.g. streaming responses) should select on it and return.
+func (ws *Workers) Done() <-chan struct{} {
+	return ws.ctx.Done()
+}
+
+// Stop cancels every worker and waits for them to return, or for ctx to
+// expire, whichever happens first.
+func (ws *Workers) Stop(ctx context.Context) error {
+	ws.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		ws.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
@@ -11,22 +12,38 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/tracing"
+	"go.opentelemetry.io/otel/codes"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func HashPassword(password string) (string, error) {
+// bcrypt is deliberately slow, so both password functions get their own
+// span to show how much of a request it takes up.
+
+func HashPassword(ctx context.Context, password string) (string, error) {
+	_, span := tracing.Tracer().Start(ctx, "bcrypt.GenerateFromPassword")
+	defer span.End()
+
 	// hash given password
 	hashedPw, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return "", err
 	}
 
 	return string(hashedPw), nil
 }
 
-func CheckPasswordHash(password, hash string) error {
+func CheckPasswordHash(ctx context.Context, password, hash string) error {
+	_, span := tracing.Tracer().Start(ctx, "bcrypt.CompareHashAndPassword")
+	defer span.End()
+
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	if err != nil {
+		// a wrong password is not a span error, it's a normal outcome.
+		if !errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			span.SetStatus(codes.Error, err.Error())
+		}
 		return err
 	}
 
@@ -0,0 +1,437 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is everything the server needs to start. values come from, in
+// increasing order of precedence:
+//
+//  1. defaults
+//  2. an optional YAML or TOML config file (--config or CONFIG_FILE)
+//  3. environment variables, including an optional .env file
+//  4. command line flags
+type Config struct {
+	DBURL    string
+	Platform string
+	Secret   string
+	PolkaKey string
+	LogLevel slog.Level
+
+	// MigrateOnStart applies pending migrations before the server starts.
+	MigrateOnStart bool
+
+	// DeletionGracePeriod is how long a deleted account can still be
+	// restored by logging in. zero deletes accounts right away.
+	DeletionGracePeriod time.Duration
+
+	Server Server
+	Media  Media
+	Chirps Chirps
+}
+
+type Server struct {
+	Addr              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
+	ShutdownDelay     time.Duration
+	MaxHeaderBytes    int
+	TLSCertFile       string
+	TLSKeyFile        string
+}
+
+// Media is where uploaded images are kept. Store is "local" (the default,
+// files under Dir) or "s3" for any S3-compatible service, e.g. MinIO.
+type Media struct {
+	Store    string
+	Dir      string
+	MaxBytes int
+
+	S3Endpoint  string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3Region    string
+	S3UseSSL    bool
+}
+
+// Chirps are the length limits of chirp bodies. lengths are counted in
+// user-perceived characters, with every link counting as URLLength.
+type Chirps struct {
+	MaxLength    int
+	MaxLengthRed int // for Chirpy Red members
+	URLLength    int
+
+	// EditWindow is how long after posting a chirp can be edited. zero
+	// disables editing.
+	EditWindow time.Duration
+}
+
+// MinSecretLength is the shortest JWT secret we accept. HS256 keys should
+// be at least as long as the hash output (256 bits).
+const MinSecretLength = 32
+
+// setting describes one configuration value. key is used as is in the
+// config file, upper-cased for the environment variable and with dashes
+// for the flag, e.g. read_timeout, READ_TIMEOUT and --read-timeout.
+type setting struct {
+	key    string
+	usage  string
+	set    func(cfg *Config, value string) error
+	isBool bool
+}
+
+func (s setting) env() string  { return strings.ToUpper(s.key) }
+func (s setting) flag() string { return strings.ReplaceAll(s.key, "_", "-") }
+
+var settings = []setting{
+	stringSetting("db_url", "Postgres connection string (required)", func(c *Config) *string { return &c.DBURL }),
+	stringSetting("platform", `"dev" enables the admin reset and fixture endpoints, "prod" disables them`, func(c *Config) *string { return &c.Platform }),
+	stringSetting("secret", fmt.Sprintf("JWT signing secret, at least %d characters (required)", MinSecretLength), func(c *Config) *string { return &c.Secret }),
+	stringSetting("polka_key", "API key Polka uses to call our webhook", func(c *Config) *string { return &c.PolkaKey }),
+	{key: "log_level", usage: "debug, info, warn or error", set: func(c *Config, v string) error { return c.LogLevel.UnmarshalText([]byte(v)) }},
+	boolSetting("migrate_on_start", "apply pending database migrations before serving", func(c *Config) *bool { return &c.MigrateOnStart }),
+	durationSetting("deletion_grace_period", "how long deleted accounts can be restored by logging in, 0 deletes them right away", func(c *Config) *time.Duration { return &c.DeletionGracePeriod }),
+	intSetting("chirp_max_length", "max length of a chirp", func(c *Config) *int { return &c.Chirps.MaxLength }),
+	intSetting("chirp_max_length_red", "max length of a chirp by a Chirpy Red member", func(c *Config) *int { return &c.Chirps.MaxLengthRed }),
+	intSetting("chirp_url_length", "how many characters a link counts as in a chirp", func(c *Config) *int { return &c.Chirps.URLLength }),
+	durationSetting("chirp_edit_window", "how long after posting a chirp can be edited, 0 disables editing", func(c *Config) *time.Duration { return &c.Chirps.EditWindow }),
+
+	stringSetting("addr", "address to listen on", func(c *Config) *string { return &c.Server.Addr }),
+	durationSetting("read_timeout", "max duration for reading an entire request", func(c *Config) *time.Duration { return &c.Server.ReadTimeout }),
+	durationSetting("read_header_timeout", "max duration for reading request headers", func(c *Config) *time.Duration { return &c.Server.ReadHeaderTimeout }),
+	durationSetting("write_timeout", "max duration before timing out writes of the response", func(c *Config) *time.Duration { return &c.Server.WriteTimeout }),
+	durationSetting("idle_timeout", "max time to wait for the next request on a keep-alive connection", func(c *Config) *time.Duration { return &c.Server.IdleTimeout }),
+	durationSetting("shutdown_timeout", "how long to wait for in-flight requests on shutdown", func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout }),
+	durationSetting("shutdown_delay", "how long to keep serving while reporting not ready before draining, so load balancers can catch up", func(c *Config) *time.Duration { return &c.Server.ShutdownDelay }),
+	intSetting("max_header_bytes", "max size of request headers", func(c *Config) *int { return &c.Server.MaxHeaderBytes }),
+	stringSetting("tls_cert_file", "TLS certificate, serves HTTPS together with tls_key_file", func(c *Config) *string { return &c.Server.TLSCertFile }),
+	stringSetting("tls_key_file", "TLS private key", func(c *Config) *string { return &c.Server.TLSKeyFile }),
+
+	stringSetting("media_store", `where uploaded media is stored, "local" or "s3"`, func(c *Config) *string { return &c.Media.Store }),
+	stringSetting("media_dir", "directory for uploaded media when media_store is local", func(c *Config) *string { return &c.Media.Dir }),
+	intSetting("media_max_bytes", "max size of an uploaded media file", func(c *Config) *int { return &c.Media.MaxBytes }),
+	stringSetting("s3_endpoint", "S3 endpoint host, e.g. s3.amazonaws.com or localhost:9000", func(c *Config) *string { return &c.Media.S3Endpoint }),
+	stringSetting("s3_bucket", "S3 bucket for uploaded media", func(c *Config) *string { return &c.Media.S3Bucket }),
+	stringSetting("s3_access_key", "S3 access key", func(c *Config) *string { return &c.Media.S3AccessKey }),
+	stringSetting("s3_secret_key", "S3 secret key", func(c *Config) *string { return &c.Media.S3SecretKey }),
+	stringSetting("s3_region", "S3 region, optional", func(c *Config) *string { return &c.Media.S3Region }),
+	boolSetting("s3_use_ssl", "connect to the S3 endpoint over HTTPS", func(c *Config) *bool { return &c.Media.S3UseSSL }),
+}
+
+func stringSetting(key, usage string, field func(*Config) *string) setting {
+	return setting{key: key, usage: usage, set: func(c *Config, v string) error {
+		*field(c) = v
+		return nil
+	}}
+}
+
+func durationSetting(key, usage string, field func(*Config) *time.Duration) setting {
+	return setting{key: key, usage: usage, set: func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = d
+		return nil
+	}}
+}
+
+func intSetting(key, usage string, field func(*Config) *int) setting {
+	return setting{key: key, usage: usage, set: func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = n
+		return nil
+	}}
+}
+
+func boolSetting(key, usage string, field func(*Config) *bool) setting {
+	return setting{key: key, usage: usage, isBool: true, set: func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = b
+		return nil
+	}}
+}
+
+// flagValue remembers whether a flag was given at all, so that only flags
+// set on the command line override the other sources.
+type flagValue struct {
+	value  string
+	set    bool
+	isBool bool
+}
+
+func (f *flagValue) String() string { return f.value }
+
+func (f *flagValue) Set(v string) error {
+	f.value, f.set = v, true
+	return nil
+}
+
+func (f *flagValue) IsBoolFlag() bool { return f.isBool }
+
+// Default returns the configuration used when nothing else is set.
+func Default() Config {
+	return Config{
+		Platform:            "prod",
+		LogLevel:            slog.LevelInfo,
+		DeletionGracePeriod: 30 * 24 * time.Hour,
+		Server: Server{
+			Addr:              ":8080",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+		},
+		Chirps: Chirps{
+			MaxLength:    140,
+			MaxLengthRed: 280,
+			URLLength:    23,
+			EditWindow:   time.Hour,
+		},
+		Media: Media{
+			Store:    "local",
+			Dir:      "media",
+			MaxBytes: 10 << 20,
+			S3UseSSL: true,
+		},
+	}
+}
+
+// Loader reads the configuration for a command. commands create their own
+// flag.FlagSet, let NewLoader register the config flags on it, add their
+// own flags and parse it before calling Load.
+type Loader struct {
+	configFile *string
+	envFile    *string
+	flags      map[string]*flagValue
+	lookupEnv  func(string) (string, bool)
+}
+
+// NewLoader registers a flag for every setting on fset.
+func NewLoader(fset *flag.FlagSet) *Loader {
+	l := &Loader{
+		configFile: fset.String("config", "", "path to a .yaml, .yml or .toml config file (env: CONFIG_FILE)"),
+		envFile:    fset.String("env-file", ".env", "path to an optional .env file"),
+		flags:      make(map[string]*flagValue, len(settings)),
+		lookupEnv:  os.LookupEnv,
+	}
+
+	for _, s := range settings {
+		l.flags[s.key] = &flagValue{isBool: s.isBool}
+		fset.Var(l.flags[s.key], s.flag(), fmt.Sprintf("%s (env: %s)", s.usage, s.env()))
+	}
+
+	return l
+}
+
+// Load builds the configuration from all sources and validates it. every
+// problem found is reported in the returned error, not just the first one.
+func (l *Loader) Load() (Config, error) {
+	cfg, err := l.Parse()
+	return cfg, errors.Join(err, cfg.Validate())
+}
+
+// Parse is Load without Validate, for commands that only need part of the
+// configuration (e.g. migrations only need db_url).
+func (l *Loader) Parse() (Config, error) {
+	cfg := Default()
+
+	// a missing .env file is fine, e.g. in containers where the variables
+	// are injected directly. a broken one is not.
+	if err := godotenv.Load(*l.envFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return cfg, fmt.Errorf("failed to load %s: %w", *l.envFile, err)
+	}
+
+	var errs []error
+	apply := func(source string, s setting, value string) {
+		if err := s.set(&cfg, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid %s %q: %w", source, s.key, value, err))
+		}
+	}
+
+	configFile := *l.configFile
+	if configFile == "" {
+		configFile, _ = l.lookupEnv("CONFIG_FILE")
+	}
+	if configFile != "" {
+		values, err := readFile(configFile)
+		if err != nil {
+			return cfg, err
+		}
+
+		for _, s := range settings {
+			if v, ok := values[s.key]; ok {
+				apply(configFile, s, v)
+				delete(values, s.key)
+			}
+		}
+		for _, key := range slices.Sorted(maps.Keys(values)) {
+			errs = append(errs, fmt.Errorf("%s: unknown setting %q", configFile, key))
+		}
+	}
+
+	for _, s := range settings {
+		if v, ok := l.lookupEnv(s.env()); ok && v != "" {
+			apply("env "+s.env(), s, v)
+		}
+	}
+
+	for _, s := range settings {
+		if f := l.flags[s.key]; f.set {
+			apply("flag --"+s.flag(), s, f.value)
+		}
+	}
+
+	return cfg, errors.Join(errs...)
+}
+
+// readFile reads a flat mapping of setting keys to scalar values, as YAML
+// or TOML depending on the file's extension.
+func readFile(path string) (map[string]string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".yaml" && ext != ".yml" && ext != ".toml" {
+		return nil, fmt.Errorf("config file %s: unsupported extension %q, use .yaml, .yml or .toml", path, ext)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var values map[string]string
+	if ext == ".toml" {
+		values, err = decodeTOML(raw)
+	} else {
+		err = yaml.Unmarshal(raw, &values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// Validate reports every invalid or missing value.
+func (cfg Config) Validate() error {
+	var errs []error
+
+	if cfg.DBURL == "" {
+		errs = append(errs, errors.New("db_url is required"))
+	}
+
+	if cfg.Secret == "" {
+		errs = append(errs, errors.New("secret is required"))
+	} else if len(cfg.Secret) < MinSecretLength {
+		errs = append(errs, fmt.Errorf("secret must be at least %d characters, got %d", MinSecretLength, len(cfg.Secret)))
+	}
+
+	if cfg.Platform != "dev" && cfg.Platform != "prod" {
+		errs = append(errs, fmt.Errorf(`platform must be "dev" or "prod", got %q`, cfg.Platform))
+	}
+
+	durations := map[string]time.Duration{
+		"read_timeout":        cfg.Server.ReadTimeout,
+		"read_header_timeout": cfg.Server.ReadHeaderTimeout,
+		"write_timeout":       cfg.Server.WriteTimeout,
+		"idle_timeout":        cfg.Server.IdleTimeout,
+		"shutdown_timeout":    cfg.Server.ShutdownTimeout,
+	}
+	for _, s := range settings {
+		if d, ok := durations[s.key]; ok && d <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %s", s.key, d))
+		}
+	}
+
+	if cfg.DeletionGracePeriod < 0 {
+		errs = append(errs, fmt.Errorf("deletion_grace_period must not be negative, got %s", cfg.DeletionGracePeriod))
+	}
+
+	if cfg.Server.ShutdownDelay < 0 {
+		errs = append(errs, fmt.Errorf("shutdown_delay must not be negative, got %s", cfg.Server.ShutdownDelay))
+	}
+
+	if cfg.Server.MaxHeaderBytes <= 0 {
+		errs = append(errs, fmt.Errorf("max_header_bytes must be positive, got %d", cfg.Server.MaxHeaderBytes))
+	}
+
+	if (cfg.Server.TLSCertFile == "") != (cfg.Server.TLSKeyFile == "") {
+		errs = append(errs, errors.New("tls_cert_file and tls_key_file must be set together"))
+	}
+
+	switch cfg.Media.Store {
+	case "local":
+		if cfg.Media.Dir == "" {
+			errs = append(errs, errors.New(`media_dir is required when media_store is "local"`))
+		}
+	case "s3":
+		for _, f := range []struct {
+			key, value string
+		}{
+			{"s3_endpoint", cfg.Media.S3Endpoint},
+			{"s3_bucket", cfg.Media.S3Bucket},
+			{"s3_access_key", cfg.Media.S3AccessKey},
+			{"s3_secret_key", cfg.Media.S3SecretKey},
+		} {
+			if f.value == "" {
+				errs = append(errs, fmt.Errorf(`%s is required when media_store is "s3"`, f.key))
+			}
+		}
+	default:
+		errs = append(errs, fmt.Errorf(`media_store must be "local" or "s3", got %q`, cfg.Media.Store))
+	}
+
+	for _, f := range []struct {
+		key   string
+		value int
+	}{
+		{"chirp_max_length", cfg.Chirps.MaxLength},
+		{"chirp_max_length_red", cfg.Chirps.MaxLengthRed},
+		{"chirp_url_length", cfg.Chirps.URLLength},
+	} {
+		if f.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %d", f.key, f.value))
+		}
+	}
+	if cfg.Chirps.URLLength > cfg.Chirps.MaxLength {
+		errs = append(errs, errors.New("chirp_url_length must not be more than chirp_max_length"))
+	}
+	if cfg.Chirps.MaxLengthRed < cfg.Chirps.MaxLength {
+		errs = append(errs, errors.New("chirp_max_length_red must not be less than chirp_max_length"))
+	}
+
+	if cfg.Chirps.EditWindow < 0 {
+		errs = append(errs, fmt.Errorf("chirp_edit_window must not be negative, got %s", cfg.Chirps.EditWindow))
+	}
+
+	if cfg.Media.MaxBytes <= 0 {
+		errs = append(errs, fmt.Errorf("media_max_bytes must be positive, got %d", cfg.Media.MaxBytes))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const TEST_SECRET = "0123456789abcdef0123456789abcdef"
+
+func mapEnv(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+// load parses args like the chirpy commands do, reading the environment
+// through lookupEnv.
+func load(args []string, lookupEnv func(string) (string, bool), output io.Writer) (Config, error) {
+	fset := flag.NewFlagSet("chirpy", flag.ContinueOnError)
+	fset.SetOutput(output)
+
+	loader := NewLoader(fset)
+	loader.lookupEnv = lookupEnv
+
+	if err := fset.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	return loader.Load()
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "chirpy.yaml")
+	err := os.WriteFile(file, []byte("addr: :7000\nread_timeout: 1s\nwrite_timeout: 2s\n"), 0o600)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	env := map[string]string{
+		"DB_URL":        "postgres://localhost/chirpy",
+		"SECRET":        TEST_SECRET,
+		"READ_TIMEOUT":  "3s",
+		"WRITE_TIMEOUT": "4s",
+	}
+	args := []string{"--env-file", "does-not-exist", "--config", file, "--write-timeout", "5s"}
+
+	cfg, err := load(args, mapEnv(env), io.Discard)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// file beats defaults, env beats file, flags beat env.
+	if cfg.Server.Addr != ":7000" {
+		t.Errorf("expected addr from file, got %s\n", cfg.Server.Addr)
+	}
+	if cfg.Server.ReadTimeout != 3*time.Second {
+		t.Errorf("expected read_timeout from env, got %s\n", cfg.Server.ReadTimeout)
+	}
+	if cfg.Server.WriteTimeout != 5*time.Second {
+		t.Errorf("expected write_timeout from flag, got %s\n", cfg.Server.WriteTimeout)
+	}
+	if cfg.Server.IdleTimeout != Default().Server.IdleTimeout {
+		t.Errorf("expected default idle_timeout, got %s\n", cfg.Server.IdleTimeout)
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	env := map[string]string{
+		"SECRET":       "too-short",
+		"PLATFORM":     "staging",
+		"IDLE_TIMEOUT": "forever",
+		"MEDIA_STORE":  "ftp",
+	}
+
+	_, err := load([]string{"--env-file", "does-not-exist"}, mapEnv(env), io.Discard)
+	if err == nil {
+		t.Fatal("expected an error\n")
+	}
+
+	for _, want := range []string{"db_url is required", "secret must be at least", "platform must be", "invalid idle_timeout", "media_store must be"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got:\n%v\n", want, err)
+		}
+	}
+}
+
+func TestReadFileTOML(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "chirpy.toml")
+	err := os.WriteFile(file, []byte(`# chirpy settings
+addr = ":7000"
+read_timeout = "1s" # trailing comment
+write_timeout = "2s"
+media_dir = 'C:\media'
+max_header_bytes = 1_048_576
+migrate_on_start = true
+polka_key = """
+abc\u00e9"""
+`), 0o600)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	values, err := readFile(file)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	want := map[string]string{
+		"addr":             ":7000",
+		"read_timeout":     "1s",
+		"write_timeout":    "2s",
+		"media_dir":        `C:\media`,
+		"max_header_bytes": "1048576",
+		"migrate_on_start": "true",
+		"polka_key":        "abc\u00e9",
+	}
+	if len(values) != len(want) {
+		t.Errorf("expected %d values, got %v\n", len(want), values)
+	}
+	for key, v := range want {
+		if values[key] != v {
+			t.Errorf("expected %s = %q, got %q\n", key, v, values[key])
+		}
+	}
+}
+
+func TestReadFileRejects(t *testing.T) {
+	tests := []struct {
+		name, contents, want string
+	}{
+		{"chirpy.json", `{"addr": ":7000"}`, "unsupported extension"},
+		{"chirpy.toml", "[server]\naddr = \":7000\"\n", "server must be a string, number or boolean"},
+		{"chirpy.toml", "addr = [\":7000\"]\n", "addr must be a string, number or boolean"},
+		{"chirpy.toml", "addr = \":7000\nread_timeout = 1s\n", "failed to parse config file"},
+		{"chirpy.toml", "addr = \":7000\"\naddr = \":8000\"\n", "failed to parse config file"},
+	}
+
+	for _, tc := range tests {
+		file := filepath.Join(t.TempDir(), tc.name)
+		if err := os.WriteFile(file, []byte(tc.contents), 0o600); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		_, err := readFile(file)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s %q: expected error containing %q, got %v\n", tc.name, tc.contents, tc.want, err)
+		}
+	}
+}
+
+func TestValidateChirpsAndS3(t *testing.T) {
+	cfg := Default()
+	cfg.DBURL = "postgres://localhost/chirpy"
+	cfg.Secret = TEST_SECRET
+	cfg.Media.Store = "s3"
+	cfg.Chirps.MaxLength = 200
+	cfg.Chirps.MaxLengthRed = 100
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected an error\n")
+	}
+
+	// errors come out in the same order every time.
+	want := strings.Join([]string{
+		`s3_endpoint is required when media_store is "s3"`,
+		`s3_bucket is required when media_store is "s3"`,
+		`s3_access_key is required when media_store is "s3"`,
+		`s3_secret_key is required when media_store is "s3"`,
+		"chirp_max_length_red must not be less than chirp_max_length",
+	}, "\n")
+	if err.Error() != want {
+		t.Errorf("expected:\n%s\ngot:\n%v\n", want, err)
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+)
+
+// decodeTOML reads a TOML config file into the same flat mapping of
+// setting keys to values as the YAML one. numbers and booleans are turned
+// back into text and parsed by the setting, like everything else; tables
+// and arrays are rejected, since every setting is a single top-level key.
+func decodeTOML(raw []byte) (map[string]string, error) {
+	var doc map[string]any
+	if _, err := toml.Decode(string(raw), &doc); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(doc))
+	for key, v := range doc {
+		switch v := v.(type) {
+		case string:
+			values[key] = v
+		case int64:
+			values[key] = strconv.FormatInt(v, 10)
+		case float64:
+			values[key] = strconv.FormatFloat(v, 'g', -1, 64)
+		case bool:
+			values[key] = strconv.FormatBool(v)
+		default:
+			return nil, fmt.Errorf("%s must be a string, number or boolean", key)
+		}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,246 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: bookmarks.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const bookmarkChirp = `-- name: BookmarkChirp :exec
+INSERT INTO bookmarks (user_id, chirp_id, created_at, folder_id)
+VALUES ($1, $2, CURRENT_TIMESTAMP, $3)
+ON CONFLICT (user_id, chirp_id) DO UPDATE SET folder_id = EXCLUDED.folder_id
+`
+
+type BookmarkChirpParams struct {
+	UserID   uuid.UUID     `json:"user_id"`
+	ChirpID  uuid.UUID     `json:"chirp_id"`
+	FolderID uuid.NullUUID `json:"folder_id"`
+}
+
+// bookmarking a chirp again moves it to folder_id, and keeps its place in
+// the list.
+func (q *Queries) BookmarkChirp(ctx context.Context, arg BookmarkChirpParams) error {
+	_, err := q.db.ExecContext(ctx, bookmarkChirp, arg.UserID, arg.ChirpID, arg.FolderID)
+	return err
+}
+
+const countBookmarkFolders = `-- name: CountBookmarkFolders :one
+SELECT COUNT(*) FROM bookmark_folders
+WHERE user_id = $1
+`
+
+func (q *Queries) CountBookmarkFolders(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countBookmarkFolders, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createBookmarkFolder = `-- name: CreateBookmarkFolder :one
+INSERT INTO bookmark_folders (id, created_at, user_id, name)
+VALUES (gen_random_uuid(), CURRENT_TIMESTAMP, $1, $2)
+RETURNING id, created_at, user_id, name
+`
+
+type CreateBookmarkFolderParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Name   string    `json:"name"`
+}
+
+func (q *Queries) CreateBookmarkFolder(ctx context.Context, arg CreateBookmarkFolderParams) (BookmarkFolder, error) {
+	row := q.db.QueryRowContext(ctx, createBookmarkFolder, arg.UserID, arg.Name)
+	var i BookmarkFolder
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UserID,
+		&i.Name,
+	)
+	return i, err
+}
+
+const deleteBookmarkFolder = `-- name: DeleteBookmarkFolder :execrows
+DELETE FROM bookmark_folders
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteBookmarkFolderParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteBookmarkFolder(ctx context.Context, arg DeleteBookmarkFolderParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteBookmarkFolder, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getBookmarkFolder = `-- name: GetBookmarkFolder :one
+SELECT id, created_at, user_id, name FROM bookmark_folders
+WHERE id = $1 AND user_id = $2
+`
+
+type GetBookmarkFolderParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) GetBookmarkFolder(ctx context.Context, arg GetBookmarkFolderParams) (BookmarkFolder, error) {
+	row := q.db.QueryRowContext(ctx, getBookmarkFolder, arg.ID, arg.UserID)
+	var i BookmarkFolder
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UserID,
+		&i.Name,
+	)
+	return i, err
+}
+
+const getBookmarkFolders = `-- name: GetBookmarkFolders :many
+SELECT id, created_at, user_id, name FROM bookmark_folders
+WHERE user_id = $1
+ORDER BY LOWER(name)
+`
+
+func (q *Queries) GetBookmarkFolders(ctx context.Context, userID uuid.UUID) ([]BookmarkFolder, error) {
+	rows, err := q.db.QueryContext(ctx, getBookmarkFolders, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BookmarkFolder
+	for rows.Next() {
+		var i BookmarkFolder
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UserID,
+			&i.Name,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBookmarks = `-- name: GetBookmarks :many
+SELECT bookmarks.created_at AS bookmarked_at,
+    bookmarks.folder_id,
+    chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.hidden_at, chirps.edited_at, chirps.status, chirps.publish_at, chirps.quoted_chirp_id,
+    users.handle AS author_handle,
+    users.display_name AS author_display_name,
+    users.avatar_url AS author_avatar_url
+FROM bookmarks
+JOIN chirps ON chirps.id = bookmarks.chirp_id
+JOIN users ON users.id = chirps.user_id AND users.delete_after IS NULL
+WHERE bookmarks.user_id = $1
+    AND ($2::UUID IS NULL OR bookmarks.folder_id = $2)
+    AND ($3::TIMESTAMPTZ IS NULL OR bookmarks.created_at < $3)
+    AND chirps.hidden_at IS NULL AND chirps.status = 'published'
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = bookmarks.user_id)
+            OR (blocks.blocker_id = bookmarks.user_id AND blocks.blocked_id = chirps.user_id)
+    )
+ORDER BY bookmarks.created_at DESC
+LIMIT $4
+`
+
+type GetBookmarksParams struct {
+	UserID   uuid.UUID     `json:"user_id"`
+	FolderID uuid.NullUUID `json:"folder_id"`
+	Before   sql.NullTime  `json:"before"`
+	RowLimit int32         `json:"row_limit"`
+}
+
+type GetBookmarksRow struct {
+	BookmarkedAt      time.Time     `json:"bookmarked_at"`
+	FolderID          uuid.NullUUID `json:"folder_id"`
+	Chirp             Chirp         `json:"chirp"`
+	AuthorHandle      string        `json:"author_handle"`
+	AuthorDisplayName string        `json:"author_display_name"`
+	AuthorAvatarUrl   string        `json:"author_avatar_url"`
+}
+
+// newest first, older than before when given, only in folder_id when given.
+// chirps the user can't see anymore (hidden, blocked, author leaving) are
+// left out but their bookmarks kept, in case they come back.
+func (q *Queries) GetBookmarks(ctx context.Context, arg GetBookmarksParams) ([]GetBookmarksRow, error) {
+	rows, err := q.db.QueryContext(ctx, getBookmarks,
+		arg.UserID,
+		arg.FolderID,
+		arg.Before,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetBookmarksRow
+	for rows.Next() {
+		var i GetBookmarksRow
+		if err := rows.Scan(
+			&i.BookmarkedAt,
+			&i.FolderID,
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.HiddenAt,
+			&i.Chirp.EditedAt,
+			&i.Chirp.Status,
+			&i.Chirp.PublishAt,
+			&i.Chirp.QuotedChirpID,
+			&i.AuthorHandle,
+			&i.AuthorDisplayName,
+			&i.AuthorAvatarUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const unbookmarkChirp = `-- name: UnbookmarkChirp :execrows
+DELETE FROM bookmarks
+WHERE user_id = $1 AND chirp_id = $2
+`
+
+type UnbookmarkChirpParams struct {
+	UserID  uuid.UUID `json:"user_id"`
+	ChirpID uuid.UUID `json:"chirp_id"`
+}
+
+func (q *Queries) UnbookmarkChirp(ctx context.Context, arg UnbookmarkChirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unbookmarkChirp, arg.UserID, arg.ChirpID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -7,27 +7,31 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const addChirp = `-- name: AddChirp :one
 INSERT INTO chirps (
-    id, created_at, updated_at, body, user_id
+    id, created_at, updated_at, body, user_id, quoted_chirp_id
 )
 VALUES (
-    gen_random_uuid(), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $1, $2
+    gen_random_uuid(), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $1, $2, $3
 )
-RETURNING id, created_at, updated_at, body, user_id
+RETURNING id, created_at, updated_at, body, user_id, hidden_at, edited_at, status, publish_at, quoted_chirp_id
 `
 
 type AddChirpParams struct {
-	Body   string    `json:"body"`
-	UserID uuid.UUID `json:"user_id"`
+	Body          string        `json:"body"`
+	UserID        uuid.UUID     `json:"user_id"`
+	QuotedChirpID uuid.NullUUID `json:"quoted_chirp_id"`
 }
 
 func (q *Queries) AddChirp(ctx context.Context, arg AddChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, addChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, addChirp, arg.Body, arg.UserID, arg.QuotedChirpID)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -35,10 +39,102 @@ func (q *Queries) AddChirp(ctx context.Context, arg AddChirpParams) (Chirp, erro
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.HiddenAt,
+		&i.EditedAt,
+		&i.Status,
+		&i.PublishAt,
+		&i.QuotedChirpID,
 	)
 	return i, err
 }
 
+const cancelScheduledChirp = `-- name: CancelScheduledChirp :execrows
+DELETE FROM chirps
+WHERE id = $1 AND user_id = $2 AND status = 'scheduled'
+`
+
+type CancelScheduledChirpParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) CancelScheduledChirp(ctx context.Context, arg CancelScheduledChirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, cancelScheduledChirp, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const countQuotes = `-- name: CountQuotes :many
+SELECT chirps.quoted_chirp_id::UUID AS chirp_id, COUNT(*) AS quote_count
+FROM chirps
+JOIN users ON users.id = chirps.user_id AND users.delete_after IS NULL
+WHERE chirps.quoted_chirp_id = ANY($1::UUID[])
+    AND chirps.hidden_at IS NULL AND chirps.status = 'published'
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $2)
+            OR (blocks.blocker_id = $2 AND blocks.blocked_id = chirps.user_id)
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM mutes
+        WHERE mutes.muter_id = $2 AND mutes.muted_id = chirps.user_id
+    )
+GROUP BY chirps.quoted_chirp_id
+`
+
+type CountQuotesParams struct {
+	Ids      []uuid.UUID   `json:"ids"`
+	ViewerID uuid.NullUUID `json:"viewer_id"`
+}
+
+type CountQuotesRow struct {
+	ChirpID    uuid.UUID `json:"chirp_id"`
+	QuoteCount int64     `json:"quote_count"`
+}
+
+// how many quotes each of the given chirps has that GetQuotes would list
+// for viewer_id. chirps without any are left out.
+func (q *Queries) CountQuotes(ctx context.Context, arg CountQuotesParams) ([]CountQuotesRow, error) {
+	rows, err := q.db.QueryContext(ctx, countQuotes, pq.Array(arg.Ids), arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountQuotesRow
+	for rows.Next() {
+		var i CountQuotesRow
+		if err := rows.Scan(&i.ChirpID, &i.QuoteCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createChirpRevision = `-- name: CreateChirpRevision :exec
+INSERT INTO chirp_revisions (id, chirp_id, created_at, body)
+VALUES (gen_random_uuid(), $1, $2, $3)
+`
+
+type CreateChirpRevisionParams struct {
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Body      string    `json:"body"`
+}
+
+func (q *Queries) CreateChirpRevision(ctx context.Context, arg CreateChirpRevisionParams) error {
+	_, err := q.db.ExecContext(ctx, createChirpRevision, arg.ChirpID, arg.CreatedAt, arg.Body)
+	return err
+}
+
 const deleteChirp = `-- name: DeleteChirp :exec
 DELETE FROM chirps
 WHERE id = $1
@@ -49,8 +145,17 @@ func (q *Queries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const deleteChirps = `-- name: DeleteChirps :exec
+DELETE FROM chirps
+`
+
+func (q *Queries) DeleteChirps(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteChirps)
+	return err
+}
+
 const getChirp = `-- name: GetChirp :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps
+SELECT id, created_at, updated_at, body, user_id, hidden_at, edited_at, status, publish_at, quoted_chirp_id FROM chirps
 WHERE id = $1
 `
 
@@ -63,12 +168,127 @@ func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.HiddenAt,
+		&i.EditedAt,
+		&i.Status,
+		&i.PublishAt,
+		&i.QuotedChirpID,
+	)
+	return i, err
+}
+
+const getChirpForUpdate = `-- name: GetChirpForUpdate :one
+SELECT id, created_at, updated_at, body, user_id, hidden_at, edited_at, status, publish_at, quoted_chirp_id FROM chirps
+WHERE id = $1
+FOR UPDATE
+`
+
+// locks the chirp until the end of the transaction, so concurrent edits
+// each get their own revision.
+func (q *Queries) GetChirpForUpdate(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpForUpdate, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.HiddenAt,
+		&i.EditedAt,
+		&i.Status,
+		&i.PublishAt,
+		&i.QuotedChirpID,
+	)
+	return i, err
+}
+
+const getChirpRevisions = `-- name: GetChirpRevisions :many
+SELECT created_at, body FROM chirp_revisions
+WHERE chirp_id = $1
+ORDER BY created_at DESC
+`
+
+type GetChirpRevisionsRow struct {
+	CreatedAt time.Time `json:"created_at"`
+	Body      string    `json:"body"`
+}
+
+func (q *Queries) GetChirpRevisions(ctx context.Context, chirpID uuid.UUID) ([]GetChirpRevisionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpRevisions, chirpID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpRevisionsRow
+	for rows.Next() {
+		var i GetChirpRevisionsRow
+		if err := rows.Scan(&i.CreatedAt, &i.Body); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpWithAuthor = `-- name: GetChirpWithAuthor :one
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.hidden_at, chirps.edited_at, chirps.status, chirps.publish_at, chirps.quoted_chirp_id,
+    users.handle AS author_handle,
+    users.display_name AS author_display_name,
+    users.avatar_url AS author_avatar_url
+FROM chirps
+JOIN users ON users.id = chirps.user_id AND users.delete_after IS NULL
+WHERE chirps.id = $1 AND chirps.hidden_at IS NULL AND chirps.status = 'published'
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $2)
+            OR (blocks.blocker_id = $2 AND blocks.blocked_id = chirps.user_id)
+    )
+`
+
+type GetChirpWithAuthorParams struct {
+	ID       uuid.UUID     `json:"id"`
+	ViewerID uuid.NullUUID `json:"viewer_id"`
+}
+
+type GetChirpWithAuthorRow struct {
+	Chirp             Chirp  `json:"chirp"`
+	AuthorHandle      string `json:"author_handle"`
+	AuthorDisplayName string `json:"author_display_name"`
+	AuthorAvatarUrl   string `json:"author_avatar_url"`
+}
+
+// viewer_id is the user asking, NULL when anonymous. chirps of users who
+// blocked them, or whom they blocked, are left out.
+func (q *Queries) GetChirpWithAuthor(ctx context.Context, arg GetChirpWithAuthorParams) (GetChirpWithAuthorRow, error) {
+	row := q.db.QueryRowContext(ctx, getChirpWithAuthor, arg.ID, arg.ViewerID)
+	var i GetChirpWithAuthorRow
+	err := row.Scan(
+		&i.Chirp.ID,
+		&i.Chirp.CreatedAt,
+		&i.Chirp.UpdatedAt,
+		&i.Chirp.Body,
+		&i.Chirp.UserID,
+		&i.Chirp.HiddenAt,
+		&i.Chirp.EditedAt,
+		&i.Chirp.Status,
+		&i.Chirp.PublishAt,
+		&i.Chirp.QuotedChirpID,
+		&i.AuthorHandle,
+		&i.AuthorDisplayName,
+		&i.AuthorAvatarUrl,
 	)
 	return i, err
 }
 
 const getChirps = `-- name: GetChirps :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
+SELECT id, created_at, updated_at, body, user_id, hidden_at, edited_at, status, publish_at, quoted_chirp_id FROM chirps
 ORDER BY created_at ASC
 `
 
@@ -87,6 +307,51 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.HiddenAt,
+			&i.EditedAt,
+			&i.Status,
+			&i.PublishAt,
+			&i.QuotedChirpID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsByUser = `-- name: GetChirpsByUser :many
+SELECT id, created_at, updated_at, body, user_id, hidden_at, edited_at, status, publish_at, quoted_chirp_id FROM chirps
+WHERE user_id = $1
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetChirpsByUser(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.HiddenAt,
+			&i.EditedAt,
+			&i.Status,
+			&i.PublishAt,
+			&i.QuotedChirpID,
 		); err != nil {
 			return nil, err
 		}
@@ -100,3 +365,420 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 	}
 	return items, nil
 }
+
+const getChirpsWithAuthors = `-- name: GetChirpsWithAuthors :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.hidden_at, chirps.edited_at, chirps.status, chirps.publish_at, chirps.quoted_chirp_id,
+    users.handle AS author_handle,
+    users.display_name AS author_display_name,
+    users.avatar_url AS author_avatar_url
+FROM chirps
+JOIN users ON users.id = chirps.user_id AND users.delete_after IS NULL
+WHERE chirps.hidden_at IS NULL AND chirps.status = 'published'
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $1)
+            OR (blocks.blocker_id = $1 AND blocks.blocked_id = chirps.user_id)
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM mutes
+        WHERE mutes.muter_id = $2 AND mutes.muted_id = chirps.user_id
+    )
+ORDER BY chirps.created_at ASC
+`
+
+type GetChirpsWithAuthorsParams struct {
+	ViewerID uuid.NullUUID `json:"viewer_id"`
+	MuterID  uuid.NullUUID `json:"muter_id"`
+}
+
+type GetChirpsWithAuthorsRow struct {
+	Chirp             Chirp  `json:"chirp"`
+	AuthorHandle      string `json:"author_handle"`
+	AuthorDisplayName string `json:"author_display_name"`
+	AuthorAvatarUrl   string `json:"author_avatar_url"`
+}
+
+// like GetChirpWithAuthor, and chirps of users muted by muter_id are left
+// out as well. muter_id is separate so mutes can be ignored when the viewer
+// asks for a muted user's chirps on purpose.
+func (q *Queries) GetChirpsWithAuthors(ctx context.Context, arg GetChirpsWithAuthorsParams) ([]GetChirpsWithAuthorsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsWithAuthors, arg.ViewerID, arg.MuterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpsWithAuthorsRow
+	for rows.Next() {
+		var i GetChirpsWithAuthorsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.HiddenAt,
+			&i.Chirp.EditedAt,
+			&i.Chirp.Status,
+			&i.Chirp.PublishAt,
+			&i.Chirp.QuotedChirpID,
+			&i.AuthorHandle,
+			&i.AuthorDisplayName,
+			&i.AuthorAvatarUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getQuotedChirps = `-- name: GetQuotedChirps :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.hidden_at, chirps.edited_at, chirps.status, chirps.publish_at, chirps.quoted_chirp_id,
+    users.handle AS author_handle,
+    users.display_name AS author_display_name,
+    users.avatar_url AS author_avatar_url
+FROM chirps
+JOIN users ON users.id = chirps.user_id AND users.delete_after IS NULL
+WHERE chirps.id = ANY($1::UUID[])
+    AND chirps.hidden_at IS NULL AND chirps.status = 'published'
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $2)
+            OR (blocks.blocker_id = $2 AND blocks.blocked_id = chirps.user_id)
+    )
+`
+
+type GetQuotedChirpsParams struct {
+	Ids      []uuid.UUID   `json:"ids"`
+	ViewerID uuid.NullUUID `json:"viewer_id"`
+}
+
+type GetQuotedChirpsRow struct {
+	Chirp             Chirp  `json:"chirp"`
+	AuthorHandle      string `json:"author_handle"`
+	AuthorDisplayName string `json:"author_display_name"`
+	AuthorAvatarUrl   string `json:"author_avatar_url"`
+}
+
+// the chirps quoted by the chirps in a response, out of the given IDs, as
+// GetChirpWithAuthor would return them to viewer_id.
+func (q *Queries) GetQuotedChirps(ctx context.Context, arg GetQuotedChirpsParams) ([]GetQuotedChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getQuotedChirps, pq.Array(arg.Ids), arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetQuotedChirpsRow
+	for rows.Next() {
+		var i GetQuotedChirpsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.HiddenAt,
+			&i.Chirp.EditedAt,
+			&i.Chirp.Status,
+			&i.Chirp.PublishAt,
+			&i.Chirp.QuotedChirpID,
+			&i.AuthorHandle,
+			&i.AuthorDisplayName,
+			&i.AuthorAvatarUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getQuotes = `-- name: GetQuotes :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.hidden_at, chirps.edited_at, chirps.status, chirps.publish_at, chirps.quoted_chirp_id,
+    users.handle AS author_handle,
+    users.display_name AS author_display_name,
+    users.avatar_url AS author_avatar_url
+FROM chirps
+JOIN users ON users.id = chirps.user_id AND users.delete_after IS NULL
+WHERE chirps.quoted_chirp_id = $1
+    AND (
+        $2::TIMESTAMPTZ IS NULL
+        OR (chirps.created_at, chirps.id) < ($2, COALESCE($3::UUID, '00000000-0000-0000-0000-000000000000'))
+    )
+    AND chirps.hidden_at IS NULL AND chirps.status = 'published'
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $4)
+            OR (blocks.blocker_id = $4 AND blocks.blocked_id = chirps.user_id)
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM mutes
+        WHERE mutes.muter_id = $4 AND mutes.muted_id = chirps.user_id
+    )
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT $5
+`
+
+type GetQuotesParams struct {
+	QuotedChirpID uuid.NullUUID `json:"quoted_chirp_id"`
+	Before        sql.NullTime  `json:"before"`
+	BeforeID      uuid.NullUUID `json:"before_id"`
+	ViewerID      uuid.NullUUID `json:"viewer_id"`
+	RowLimit      int32         `json:"row_limit"`
+}
+
+type GetQuotesRow struct {
+	Chirp             Chirp  `json:"chirp"`
+	AuthorHandle      string `json:"author_handle"`
+	AuthorDisplayName string `json:"author_display_name"`
+	AuthorAvatarUrl   string `json:"author_avatar_url"`
+}
+
+// the quotes of a chirp, newest first, as GetChirpsWithAuthors would return
+// them. older than before when given; before_id breaks ties between quotes
+// published at the same time when paging through them.
+func (q *Queries) GetQuotes(ctx context.Context, arg GetQuotesParams) ([]GetQuotesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getQuotes,
+		arg.QuotedChirpID,
+		arg.Before,
+		arg.BeforeID,
+		arg.ViewerID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetQuotesRow
+	for rows.Next() {
+		var i GetQuotesRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.HiddenAt,
+			&i.Chirp.EditedAt,
+			&i.Chirp.Status,
+			&i.Chirp.PublishAt,
+			&i.Chirp.QuotedChirpID,
+			&i.AuthorHandle,
+			&i.AuthorDisplayName,
+			&i.AuthorAvatarUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getScheduledChirps = `-- name: GetScheduledChirps :many
+SELECT id, created_at, updated_at, body, user_id, hidden_at, edited_at, status, publish_at, quoted_chirp_id FROM chirps
+WHERE user_id = $1 AND status = 'scheduled'
+ORDER BY publish_at ASC
+`
+
+func (q *Queries) GetScheduledChirps(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getScheduledChirps, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.HiddenAt,
+			&i.EditedAt,
+			&i.Status,
+			&i.PublishAt,
+			&i.QuotedChirpID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const publishDueChirps = `-- name: PublishDueChirps :many
+UPDATE chirps
+SET status = 'published', created_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT id FROM chirps
+    WHERE status = 'scheduled' AND publish_at <= CURRENT_TIMESTAMP
+    ORDER BY publish_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+) AND status = 'scheduled'
+RETURNING id, created_at, updated_at, body, user_id, hidden_at, edited_at, status, publish_at, quoted_chirp_id
+`
+
+// publishes up to batch_size chirps that are due. rows another replica is
+// already publishing are skipped instead of waited for, and the status
+// check in the UPDATE keeps a chirp from being published twice.
+func (q *Queries) PublishDueChirps(ctx context.Context, batchSize int32) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, publishDueChirps, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.HiddenAt,
+			&i.EditedAt,
+			&i.Status,
+			&i.PublishAt,
+			&i.QuotedChirpID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rescheduleChirp = `-- name: RescheduleChirp :one
+UPDATE chirps
+SET publish_at = $3, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND user_id = $2 AND status = 'scheduled'
+RETURNING id, created_at, updated_at, body, user_id, hidden_at, edited_at, status, publish_at, quoted_chirp_id
+`
+
+type RescheduleChirpParams struct {
+	ID        uuid.UUID    `json:"id"`
+	UserID    uuid.UUID    `json:"user_id"`
+	PublishAt sql.NullTime `json:"publish_at"`
+}
+
+func (q *Queries) RescheduleChirp(ctx context.Context, arg RescheduleChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, rescheduleChirp, arg.ID, arg.UserID, arg.PublishAt)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.HiddenAt,
+		&i.EditedAt,
+		&i.Status,
+		&i.PublishAt,
+		&i.QuotedChirpID,
+	)
+	return i, err
+}
+
+const scheduleChirp = `-- name: ScheduleChirp :one
+INSERT INTO chirps (
+    id, created_at, updated_at, body, user_id, status, publish_at, quoted_chirp_id
+)
+VALUES (
+    gen_random_uuid(), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $1, $2, 'scheduled', $3, $4
+)
+RETURNING id, created_at, updated_at, body, user_id, hidden_at, edited_at, status, publish_at, quoted_chirp_id
+`
+
+type ScheduleChirpParams struct {
+	Body          string        `json:"body"`
+	UserID        uuid.UUID     `json:"user_id"`
+	PublishAt     sql.NullTime  `json:"publish_at"`
+	QuotedChirpID uuid.NullUUID `json:"quoted_chirp_id"`
+}
+
+func (q *Queries) ScheduleChirp(ctx context.Context, arg ScheduleChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, scheduleChirp,
+		arg.Body,
+		arg.UserID,
+		arg.PublishAt,
+		arg.QuotedChirpID,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.HiddenAt,
+		&i.EditedAt,
+		&i.Status,
+		&i.PublishAt,
+		&i.QuotedChirpID,
+	)
+	return i, err
+}
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $2, updated_at = CURRENT_TIMESTAMP, edited_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, created_at, updated_at, body, user_id, hidden_at, edited_at, status, publish_at, quoted_chirp_id
+`
+
+type UpdateChirpBodyParams struct {
+	ID   uuid.UUID `json:"id"`
+	Body string    `json:"body"`
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody, arg.ID, arg.Body)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.HiddenAt,
+		&i.EditedAt,
+		&i.Status,
+		&i.PublishAt,
+		&i.QuotedChirpID,
+	)
+	return i, err
+}
@@ -0,0 +1,189 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: drafts.sql
+
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+const countDrafts = `-- name: CountDrafts :one
+SELECT COUNT(*) FROM drafts
+WHERE user_id = $1
+`
+
+func (q *Queries) CountDrafts(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countDrafts, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createDraft = `-- name: CreateDraft :one
+INSERT INTO drafts (id, created_at, updated_at, user_id, body, media)
+VALUES (gen_random_uuid(), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $1, $2, $3)
+RETURNING id, created_at, updated_at, user_id, body, media
+`
+
+type CreateDraftParams struct {
+	UserID uuid.UUID       `json:"user_id"`
+	Body   string          `json:"body"`
+	Media  json.RawMessage `json:"media"`
+}
+
+func (q *Queries) CreateDraft(ctx context.Context, arg CreateDraftParams) (Draft, error) {
+	row := q.db.QueryRowContext(ctx, createDraft, arg.UserID, arg.Body, arg.Media)
+	var i Draft
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Body,
+		&i.Media,
+	)
+	return i, err
+}
+
+const deleteDraft = `-- name: DeleteDraft :execrows
+DELETE FROM drafts
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteDraftParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteDraft(ctx context.Context, arg DeleteDraftParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteDraft, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getDraft = `-- name: GetDraft :one
+SELECT id, created_at, updated_at, user_id, body, media FROM drafts
+WHERE id = $1 AND user_id = $2
+`
+
+type GetDraftParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) GetDraft(ctx context.Context, arg GetDraftParams) (Draft, error) {
+	row := q.db.QueryRowContext(ctx, getDraft, arg.ID, arg.UserID)
+	var i Draft
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Body,
+		&i.Media,
+	)
+	return i, err
+}
+
+const getDraftForUpdate = `-- name: GetDraftForUpdate :one
+SELECT id, created_at, updated_at, user_id, body, media FROM drafts
+WHERE id = $1 AND user_id = $2
+FOR UPDATE
+`
+
+type GetDraftForUpdateParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// locks the draft until the end of the transaction, so it's published at
+// most once.
+func (q *Queries) GetDraftForUpdate(ctx context.Context, arg GetDraftForUpdateParams) (Draft, error) {
+	row := q.db.QueryRowContext(ctx, getDraftForUpdate, arg.ID, arg.UserID)
+	var i Draft
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Body,
+		&i.Media,
+	)
+	return i, err
+}
+
+const getDrafts = `-- name: GetDrafts :many
+SELECT id, created_at, updated_at, user_id, body, media FROM drafts
+WHERE user_id = $1
+ORDER BY updated_at DESC
+`
+
+func (q *Queries) GetDrafts(ctx context.Context, userID uuid.UUID) ([]Draft, error) {
+	rows, err := q.db.QueryContext(ctx, getDrafts, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Draft
+	for rows.Next() {
+		var i Draft
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Body,
+			&i.Media,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateDraft = `-- name: UpdateDraft :one
+UPDATE drafts
+SET body = $3, media = $4, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND user_id = $2
+RETURNING id, created_at, updated_at, user_id, body, media
+`
+
+type UpdateDraftParams struct {
+	ID     uuid.UUID       `json:"id"`
+	UserID uuid.UUID       `json:"user_id"`
+	Body   string          `json:"body"`
+	Media  json.RawMessage `json:"media"`
+}
+
+func (q *Queries) UpdateDraft(ctx context.Context, arg UpdateDraftParams) (Draft, error) {
+	row := q.db.QueryRowContext(ctx, updateDraft,
+		arg.ID,
+		arg.UserID,
+		arg.Body,
+		arg.Media,
+	)
+	var i Draft
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Body,
+		&i.Media,
+	)
+	return i, err
+}
@@ -0,0 +1,34 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// unique constraints that handlers report to clients, see IsUniqueViolation.
+const (
+	UsersEmailKey  = "users_email_key"
+	UsersHandleKey = "users_handle_key"
+
+	ChirpMediaMediaIDKey = "chirp_media_media_id_key"
+	FilterRulesWordKey   = "filter_rules_word_key"
+
+	ReportsOpenChirpKey = "reports_open_chirp_key"
+	ReportsOpenUserKey  = "reports_open_user_key"
+
+	MutedWordsUserPatternKey = "muted_words_user_pattern_key"
+
+	BookmarkFoldersUserNameKey = "bookmark_folders_user_name_key"
+)
+
+// IsUniqueViolation reports whether err comes from inserting or updating a
+// row that breaks the given unique constraint, e.g. an email that's already
+// taken. an empty constraint matches any of them.
+func IsUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "23505" {
+		return false
+	}
+	return constraint == "" || pqErr.Constraint == constraint
+}
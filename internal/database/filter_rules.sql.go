@@ -0,0 +1,174 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: filter_rules.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createFilterFlag = `-- name: CreateFilterFlag :exec
+INSERT INTO filter_flags (id, created_at, chirp_id, rule_id, matched)
+VALUES (gen_random_uuid(), CURRENT_TIMESTAMP, $1, $2, $3)
+`
+
+type CreateFilterFlagParams struct {
+	ChirpID uuid.UUID     `json:"chirp_id"`
+	RuleID  uuid.NullUUID `json:"rule_id"`
+	Matched string        `json:"matched"`
+}
+
+func (q *Queries) CreateFilterFlag(ctx context.Context, arg CreateFilterFlagParams) error {
+	_, err := q.db.ExecContext(ctx, createFilterFlag, arg.ChirpID, arg.RuleID, arg.Matched)
+	return err
+}
+
+const createFilterRule = `-- name: CreateFilterRule :one
+INSERT INTO filter_rules (id, created_at, updated_at, word, action)
+VALUES (gen_random_uuid(), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $1, $2)
+RETURNING id, created_at, updated_at, word, action
+`
+
+type CreateFilterRuleParams struct {
+	Word   string `json:"word"`
+	Action string `json:"action"`
+}
+
+func (q *Queries) CreateFilterRule(ctx context.Context, arg CreateFilterRuleParams) (FilterRule, error) {
+	row := q.db.QueryRowContext(ctx, createFilterRule, arg.Word, arg.Action)
+	var i FilterRule
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Word,
+		&i.Action,
+	)
+	return i, err
+}
+
+const deleteFilterRule = `-- name: DeleteFilterRule :execrows
+DELETE FROM filter_rules
+WHERE id = $1
+`
+
+func (q *Queries) DeleteFilterRule(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFilterRule, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listFilterFlags = `-- name: ListFilterFlags :many
+SELECT filter_flags.id, filter_flags.created_at, filter_flags.chirp_id, filter_flags.rule_id, filter_flags.matched, chirps.body AS chirp_body, chirps.user_id AS chirp_user_id
+FROM filter_flags
+JOIN chirps ON chirps.id = filter_flags.chirp_id
+ORDER BY filter_flags.created_at DESC
+LIMIT $1
+`
+
+type ListFilterFlagsRow struct {
+	ID          uuid.UUID     `json:"id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	ChirpID     uuid.UUID     `json:"chirp_id"`
+	RuleID      uuid.NullUUID `json:"rule_id"`
+	Matched     string        `json:"matched"`
+	ChirpBody   string        `json:"chirp_body"`
+	ChirpUserID uuid.UUID     `json:"chirp_user_id"`
+}
+
+func (q *Queries) ListFilterFlags(ctx context.Context, limit int32) ([]ListFilterFlagsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFilterFlags, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFilterFlagsRow
+	for rows.Next() {
+		var i ListFilterFlagsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.ChirpID,
+			&i.RuleID,
+			&i.Matched,
+			&i.ChirpBody,
+			&i.ChirpUserID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFilterRules = `-- name: ListFilterRules :many
+SELECT id, created_at, updated_at, word, action FROM filter_rules
+ORDER BY word
+`
+
+func (q *Queries) ListFilterRules(ctx context.Context) ([]FilterRule, error) {
+	rows, err := q.db.QueryContext(ctx, listFilterRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FilterRule
+	for rows.Next() {
+		var i FilterRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Word,
+			&i.Action,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateFilterRule = `-- name: UpdateFilterRule :one
+UPDATE filter_rules
+SET action = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, created_at, updated_at, word, action
+`
+
+type UpdateFilterRuleParams struct {
+	ID     uuid.UUID `json:"id"`
+	Action string    `json:"action"`
+}
+
+func (q *Queries) UpdateFilterRule(ctx context.Context, arg UpdateFilterRuleParams) (FilterRule, error) {
+	row := q.db.QueryRowContext(ctx, updateFilterRule, arg.ID, arg.Action)
+	var i FilterRule
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Word,
+		&i.Action,
+	)
+	return i, err
+}
@@ -0,0 +1,102 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: fixtures.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const insertFixtureChirp = `-- name: InsertFixtureChirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+VALUES (
+    $1, $2, $2, $3, $4
+)
+RETURNING id, created_at, updated_at, body, user_id, hidden_at, edited_at, status, publish_at, quoted_chirp_id
+`
+
+type InsertFixtureChirpParams struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Body      string    `json:"body"`
+	UserID    uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) InsertFixtureChirp(ctx context.Context, arg InsertFixtureChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, insertFixtureChirp,
+		arg.ID,
+		arg.CreatedAt,
+		arg.Body,
+		arg.UserID,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.HiddenAt,
+		&i.EditedAt,
+		&i.Status,
+		&i.PublishAt,
+		&i.QuotedChirpID,
+	)
+	return i, err
+}
+
+const insertFixtureUser = `-- name: InsertFixtureUser :one
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio)
+VALUES (
+    $1, $2, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, suspended_at, handle, display_name, bio, location, website, avatar_url, delete_after
+`
+
+type InsertFixtureUserParams struct {
+	ID             uuid.UUID `json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	Email          string    `json:"email"`
+	HashedPassword string    `json:"hashed_password"`
+	IsChirpyRed    bool      `json:"is_chirpy_red"`
+	Handle         string    `json:"handle"`
+	DisplayName    string    `json:"display_name"`
+	Bio            string    `json:"bio"`
+}
+
+func (q *Queries) InsertFixtureUser(ctx context.Context, arg InsertFixtureUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, insertFixtureUser,
+		arg.ID,
+		arg.CreatedAt,
+		arg.Email,
+		arg.HashedPassword,
+		arg.IsChirpyRed,
+		arg.Handle,
+		arg.DisplayName,
+		arg.Bio,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Role,
+		&i.SuspendedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.Website,
+		&i.AvatarUrl,
+		&i.DeleteAfter,
+	)
+	return i, err
+}
@@ -0,0 +1,330 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: media.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const attachMedia = `-- name: AttachMedia :exec
+INSERT INTO chirp_media (chirp_id, media_id, position, alt_text)
+VALUES ($1, $2, $3, $4)
+`
+
+type AttachMediaParams struct {
+	ChirpID  uuid.UUID `json:"chirp_id"`
+	MediaID  uuid.UUID `json:"media_id"`
+	Position int16     `json:"position"`
+	AltText  string    `json:"alt_text"`
+}
+
+func (q *Queries) AttachMedia(ctx context.Context, arg AttachMediaParams) error {
+	_, err := q.db.ExecContext(ctx, attachMedia,
+		arg.ChirpID,
+		arg.MediaID,
+		arg.Position,
+		arg.AltText,
+	)
+	return err
+}
+
+const createMedia = `-- name: CreateMedia :one
+INSERT INTO media (
+    id, created_at, user_id, content_type, size_bytes, width, height,
+    storage_key, thumbnail_key, thumbnail_content_type
+)
+VALUES (
+    $1, CURRENT_TIMESTAMP, $2, $3, $4, $5, $6, $7, $8, $9
+)
+RETURNING id, created_at, user_id, content_type, size_bytes, width, height, storage_key, thumbnail_key, thumbnail_content_type
+`
+
+type CreateMediaParams struct {
+	ID                   uuid.UUID `json:"id"`
+	UserID               uuid.UUID `json:"user_id"`
+	ContentType          string    `json:"content_type"`
+	SizeBytes            int64     `json:"size_bytes"`
+	Width                int32     `json:"width"`
+	Height               int32     `json:"height"`
+	StorageKey           string    `json:"storage_key"`
+	ThumbnailKey         string    `json:"thumbnail_key"`
+	ThumbnailContentType string    `json:"thumbnail_content_type"`
+}
+
+func (q *Queries) CreateMedia(ctx context.Context, arg CreateMediaParams) (Medium, error) {
+	row := q.db.QueryRowContext(ctx, createMedia,
+		arg.ID,
+		arg.UserID,
+		arg.ContentType,
+		arg.SizeBytes,
+		arg.Width,
+		arg.Height,
+		arg.StorageKey,
+		arg.ThumbnailKey,
+		arg.ThumbnailContentType,
+	)
+	var i Medium
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UserID,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Width,
+		&i.Height,
+		&i.StorageKey,
+		&i.ThumbnailKey,
+		&i.ThumbnailContentType,
+	)
+	return i, err
+}
+
+const deleteAbandonedMedia = `-- name: DeleteAbandonedMedia :execrows
+DELETE FROM media
+WHERE media.created_at < $1
+    AND NOT EXISTS (SELECT 1 FROM chirp_media WHERE chirp_media.media_id = media.id)
+    AND NOT EXISTS (
+        SELECT 1 FROM drafts
+        WHERE drafts.user_id = media.user_id
+            AND drafts.media @> jsonb_build_array(jsonb_build_object('id', media.id::TEXT))
+    )
+`
+
+// uploads that were never attached, or whose chirp is gone, and that no
+// draft refers to, once they're older than created_before. deleting them
+// queues their blobs for deletion.
+func (q *Queries) DeleteAbandonedMedia(ctx context.Context, createdBefore time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAbandonedMedia, createdBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteMediaBlobDeletion = `-- name: DeleteMediaBlobDeletion :exec
+DELETE FROM media_blob_deletions
+WHERE storage_key = $1
+`
+
+func (q *Queries) DeleteMediaBlobDeletion(ctx context.Context, storageKey string) error {
+	_, err := q.db.ExecContext(ctx, deleteMediaBlobDeletion, storageKey)
+	return err
+}
+
+const getChirpMedia = `-- name: GetChirpMedia :many
+SELECT chirp_media.chirp_id, chirp_media.position, chirp_media.alt_text, media.id, media.created_at, media.user_id, media.content_type, media.size_bytes, media.width, media.height, media.storage_key, media.thumbnail_key, media.thumbnail_content_type
+FROM chirp_media
+JOIN media ON media.id = chirp_media.media_id
+WHERE chirp_media.chirp_id = ANY($1::UUID[])
+ORDER BY chirp_media.chirp_id, chirp_media.position
+`
+
+type GetChirpMediaRow struct {
+	ChirpID  uuid.UUID `json:"chirp_id"`
+	Position int16     `json:"position"`
+	AltText  string    `json:"alt_text"`
+	Medium   Medium    `json:"medium"`
+}
+
+func (q *Queries) GetChirpMedia(ctx context.Context, chirpIds []uuid.UUID) ([]GetChirpMediaRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpMedia, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpMediaRow
+	for rows.Next() {
+		var i GetChirpMediaRow
+		if err := rows.Scan(
+			&i.ChirpID,
+			&i.Position,
+			&i.AltText,
+			&i.Medium.ID,
+			&i.Medium.CreatedAt,
+			&i.Medium.UserID,
+			&i.Medium.ContentType,
+			&i.Medium.SizeBytes,
+			&i.Medium.Width,
+			&i.Medium.Height,
+			&i.Medium.StorageKey,
+			&i.Medium.ThumbnailKey,
+			&i.Medium.ThumbnailContentType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMedia = `-- name: GetMedia :one
+SELECT id, created_at, user_id, content_type, size_bytes, width, height, storage_key, thumbnail_key, thumbnail_content_type FROM media
+WHERE id = $1
+`
+
+func (q *Queries) GetMedia(ctx context.Context, id uuid.UUID) (Medium, error) {
+	row := q.db.QueryRowContext(ctx, getMedia, id)
+	var i Medium
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UserID,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Width,
+		&i.Height,
+		&i.StorageKey,
+		&i.ThumbnailKey,
+		&i.ThumbnailContentType,
+	)
+	return i, err
+}
+
+const getMediaBlobDeletions = `-- name: GetMediaBlobDeletions :many
+SELECT storage_key FROM media_blob_deletions
+ORDER BY created_at
+LIMIT $1
+`
+
+func (q *Queries) GetMediaBlobDeletions(ctx context.Context, limit int32) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getMediaBlobDeletions, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var storage_key string
+		if err := rows.Scan(&storage_key); err != nil {
+			return nil, err
+		}
+		items = append(items, storage_key)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnattachedMedia = `-- name: GetUnattachedMedia :many
+SELECT media.id, media.created_at, media.user_id, media.content_type, media.size_bytes, media.width, media.height, media.storage_key, media.thumbnail_key, media.thumbnail_content_type FROM media
+LEFT JOIN chirp_media ON chirp_media.media_id = media.id
+WHERE media.id = ANY($1::UUID[])
+    AND media.user_id = $2
+    AND chirp_media.media_id IS NULL
+`
+
+type GetUnattachedMediaParams struct {
+	Ids    []uuid.UUID `json:"ids"`
+	UserID uuid.UUID   `json:"user_id"`
+}
+
+// media owned by the user that isn't on a chirp yet, out of the given IDs.
+func (q *Queries) GetUnattachedMedia(ctx context.Context, arg GetUnattachedMediaParams) ([]Medium, error) {
+	rows, err := q.db.QueryContext(ctx, getUnattachedMedia, pq.Array(arg.Ids), arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Medium
+	for rows.Next() {
+		var i Medium
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UserID,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Width,
+			&i.Height,
+			&i.StorageKey,
+			&i.ThumbnailKey,
+			&i.ThumbnailContentType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getVisibleMedia = `-- name: GetVisibleMedia :one
+SELECT media.id, media.created_at, media.user_id, media.content_type, media.size_bytes, media.width, media.height, media.storage_key, media.thumbnail_key, media.thumbnail_content_type FROM media
+JOIN users ON users.id = media.user_id AND users.delete_after IS NULL
+LEFT JOIN chirp_media ON chirp_media.media_id = media.id
+LEFT JOIN chirps ON chirps.id = chirp_media.chirp_id
+WHERE media.id = $1
+    AND (
+        (chirps.id IS NULL AND media.user_id = $2)
+        OR (
+            chirps.hidden_at IS NULL
+            AND (chirps.status = 'published' OR chirps.user_id = $2)
+            AND NOT EXISTS (
+                SELECT 1 FROM blocks
+                WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $2)
+                    OR (blocks.blocker_id = $2 AND blocks.blocked_id = chirps.user_id)
+            )
+        )
+    )
+`
+
+type GetVisibleMediaParams struct {
+	ID       uuid.UUID     `json:"id"`
+	ViewerID uuid.NullUUID `json:"viewer_id"`
+}
+
+// media as viewer_id (NULL when anonymous) may see it: on a chirp they can
+// see, like GetChirpWithAuthor, or not attached yet and their own upload.
+// authors can see the media of their own scheduled chirps.
+func (q *Queries) GetVisibleMedia(ctx context.Context, arg GetVisibleMediaParams) (Medium, error) {
+	row := q.db.QueryRowContext(ctx, getVisibleMedia, arg.ID, arg.ViewerID)
+	var i Medium
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UserID,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Width,
+		&i.Height,
+		&i.StorageKey,
+		&i.ThumbnailKey,
+		&i.ThumbnailContentType,
+	)
+	return i, err
+}
+
+const queueMediaBlobDeletion = `-- name: QueueMediaBlobDeletion :exec
+INSERT INTO media_blob_deletions (storage_key, created_at)
+VALUES ($1, CURRENT_TIMESTAMP)
+ON CONFLICT DO NOTHING
+`
+
+// queues a blob that never got a media row, e.g. from a failed upload, for
+// chirpy.CleanUpMedia.
+func (q *Queries) QueueMediaBlobDeletion(ctx context.Context, storageKey string) error {
+	_, err := q.db.ExecContext(ctx, queueMediaBlobDeletion, storageKey)
+	return err
+}
@@ -0,0 +1,48 @@
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+
+	"github.com/johndosdos/chirpy/internal/database/sql/schema"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// NewProvider returns a goose provider for the embedded schema migrations.
+// up and down take a Postgres advisory lock for their whole run, so
+// several replicas starting with --migrate-on-start at the same time apply
+// each migration exactly once: the first one migrates, the others wait and
+// then find nothing left to do.
+func NewProvider(db *sql.DB) (*goose.Provider, error) {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration lock: %w", err)
+	}
+
+	return goose.NewProvider(goose.DialectPostgres, db, schema.FS,
+		goose.WithSessionLocker(locker),
+	)
+}
+
+// LatestVersion returns the version of the newest embedded migration, i.e.
+// the version a fully migrated database is at.
+func LatestVersion() int64 {
+	names, err := fs.Glob(schema.FS, "*.sql")
+	if err != nil {
+		// only possible with a malformed pattern.
+		panic(err)
+	}
+
+	var latest int64
+	for _, name := range names {
+		version, err := goose.NumericComponent(name)
+		if err != nil {
+			panic(fmt.Sprintf("invalid migration file name %s: %v", name, err))
+		}
+		latest = max(latest, version)
+	}
+
+	return latest
+}
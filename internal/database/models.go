@@ -6,17 +6,134 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type Block struct {
+	BlockerID uuid.UUID `json:"blocker_id"`
+	BlockedID uuid.UUID `json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Bookmark struct {
+	UserID    uuid.UUID     `json:"user_id"`
+	ChirpID   uuid.UUID     `json:"chirp_id"`
+	CreatedAt time.Time     `json:"created_at"`
+	FolderID  uuid.NullUUID `json:"folder_id"`
+}
+
+type BookmarkFolder struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+}
+
 type Chirp struct {
+	ID            uuid.UUID     `json:"id"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	Body          string        `json:"body"`
+	UserID        uuid.UUID     `json:"user_id"`
+	HiddenAt      sql.NullTime  `json:"hidden_at"`
+	EditedAt      sql.NullTime  `json:"edited_at"`
+	Status        string        `json:"status"`
+	PublishAt     sql.NullTime  `json:"publish_at"`
+	QuotedChirpID uuid.NullUUID `json:"quoted_chirp_id"`
+}
+
+type ChirpMedium struct {
+	ChirpID  uuid.UUID `json:"chirp_id"`
+	MediaID  uuid.UUID `json:"media_id"`
+	Position int16     `json:"position"`
+	AltText  string    `json:"alt_text"`
+}
+
+type ChirpRevision struct {
 	ID        uuid.UUID `json:"id"`
+	ChirpID   uuid.UUID `json:"chirp_id"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
 	Body      string    `json:"body"`
-	UserID    uuid.UUID `json:"user_id"`
+}
+
+type Draft struct {
+	ID        uuid.UUID       `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	UserID    uuid.UUID       `json:"user_id"`
+	Body      string          `json:"body"`
+	Media     json.RawMessage `json:"media"`
+}
+
+type FilterFlag struct {
+	ID        uuid.UUID     `json:"id"`
+	CreatedAt time.Time     `json:"created_at"`
+	ChirpID   uuid.UUID     `json:"chirp_id"`
+	RuleID    uuid.NullUUID `json:"rule_id"`
+	Matched   string        `json:"matched"`
+}
+
+type FilterRule struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Word      string    `json:"word"`
+	Action    string    `json:"action"`
+}
+
+type MediaBlobDeletion struct {
+	StorageKey string    `json:"storage_key"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type Medium struct {
+	ID                   uuid.UUID `json:"id"`
+	CreatedAt            time.Time `json:"created_at"`
+	UserID               uuid.UUID `json:"user_id"`
+	ContentType          string    `json:"content_type"`
+	SizeBytes            int64     `json:"size_bytes"`
+	Width                int32     `json:"width"`
+	Height               int32     `json:"height"`
+	StorageKey           string    `json:"storage_key"`
+	ThumbnailKey         string    `json:"thumbnail_key"`
+	ThumbnailContentType string    `json:"thumbnail_content_type"`
+}
+
+type ModerationAudit struct {
+	ID          uuid.UUID     `json:"id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	ModeratorID uuid.NullUUID `json:"moderator_id"`
+	ReportID    uuid.NullUUID `json:"report_id"`
+	Action      string        `json:"action"`
+	Note        string        `json:"note"`
+}
+
+type Mute struct {
+	MuterID   uuid.UUID `json:"muter_id"`
+	MutedID   uuid.UUID `json:"muted_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MutedWord struct {
+	ID        uuid.UUID    `json:"id"`
+	CreatedAt time.Time    `json:"created_at"`
+	UserID    uuid.UUID    `json:"user_id"`
+	Pattern   string       `json:"pattern"`
+	Mode      string       `json:"mode"`
+	ExpiresAt sql.NullTime `json:"expires_at"`
+}
+
+type Notification struct {
+	ID        uuid.UUID     `json:"id"`
+	CreatedAt time.Time     `json:"created_at"`
+	UserID    uuid.UUID     `json:"user_id"`
+	Type      string        `json:"type"`
+	ReportID  uuid.NullUUID `json:"report_id"`
+	Message   string        `json:"message"`
+	ReadAt    sql.NullTime  `json:"read_at"`
 }
 
 type RefreshToken struct {
@@ -28,11 +145,37 @@ type RefreshToken struct {
 	RevokedAt sql.NullTime `json:"revoked_at"`
 }
 
+type Report struct {
+	ID             uuid.UUID      `json:"id"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	ReporterID     uuid.UUID      `json:"reporter_id"`
+	ReportedUserID uuid.UUID      `json:"reported_user_id"`
+	ChirpID        uuid.NullUUID  `json:"chirp_id"`
+	Reason         string         `json:"reason"`
+	Details        string         `json:"details"`
+	Status         string         `json:"status"`
+	ClaimedBy      uuid.NullUUID  `json:"claimed_by"`
+	ClaimedAt      sql.NullTime   `json:"claimed_at"`
+	Resolution     sql.NullString `json:"resolution"`
+	ResolutionNote string         `json:"resolution_note"`
+	ResolvedAt     sql.NullTime   `json:"resolved_at"`
+}
+
 type User struct {
-	ID             uuid.UUID `json:"id"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
-	Email          string    `json:"email"`
-	HashedPassword string    `json:"hashed_password"`
-	IsChirpyRed    bool      `json:"is_chirpy_red"`
+	ID             uuid.UUID    `json:"id"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	Email          string       `json:"email"`
+	HashedPassword string       `json:"hashed_password"`
+	IsChirpyRed    bool         `json:"is_chirpy_red"`
+	Role           string       `json:"role"`
+	SuspendedAt    sql.NullTime `json:"suspended_at"`
+	Handle         string       `json:"handle"`
+	DisplayName    string       `json:"display_name"`
+	Bio            string       `json:"bio"`
+	Location       string       `json:"location"`
+	Website        string       `json:"website"`
+	AvatarUrl      string       `json:"avatar_url"`
+	DeleteAfter    sql.NullTime `json:"delete_after"`
 }
@@ -0,0 +1,126 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: muted_words.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const countActiveMutedWords = `-- name: CountActiveMutedWords :one
+SELECT COUNT(*) FROM muted_words
+WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+`
+
+func (q *Queries) CountActiveMutedWords(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countActiveMutedWords, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createMutedWord = `-- name: CreateMutedWord :one
+INSERT INTO muted_words (id, created_at, user_id, pattern, mode, expires_at)
+VALUES (gen_random_uuid(), CURRENT_TIMESTAMP, $1, $2, $3, $4)
+RETURNING id, created_at, user_id, pattern, mode, expires_at
+`
+
+type CreateMutedWordParams struct {
+	UserID    uuid.UUID    `json:"user_id"`
+	Pattern   string       `json:"pattern"`
+	Mode      string       `json:"mode"`
+	ExpiresAt sql.NullTime `json:"expires_at"`
+}
+
+func (q *Queries) CreateMutedWord(ctx context.Context, arg CreateMutedWordParams) (MutedWord, error) {
+	row := q.db.QueryRowContext(ctx, createMutedWord,
+		arg.UserID,
+		arg.Pattern,
+		arg.Mode,
+		arg.ExpiresAt,
+	)
+	var i MutedWord
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UserID,
+		&i.Pattern,
+		&i.Mode,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const deleteExpiredMutedWords = `-- name: DeleteExpiredMutedWords :execrows
+DELETE FROM muted_words
+WHERE user_id = $1 AND expires_at <= CURRENT_TIMESTAMP
+`
+
+// expired entries stop applying on their own; this only keeps them from
+// piling up and blocking the same pattern from being muted again.
+func (q *Queries) DeleteExpiredMutedWords(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredMutedWords, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteMutedWord = `-- name: DeleteMutedWord :execrows
+DELETE FROM muted_words
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteMutedWordParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteMutedWord(ctx context.Context, arg DeleteMutedWordParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteMutedWord, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getActiveMutedWords = `-- name: GetActiveMutedWords :many
+SELECT id, created_at, user_id, pattern, mode, expires_at FROM muted_words
+WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+ORDER BY created_at DESC
+`
+
+func (q *Queries) GetActiveMutedWords(ctx context.Context, userID uuid.UUID) ([]MutedWord, error) {
+	rows, err := q.db.QueryContext(ctx, getActiveMutedWords, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MutedWord
+	for rows.Next() {
+		var i MutedWord
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UserID,
+			&i.Pattern,
+			&i.Mode,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
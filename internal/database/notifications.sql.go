@@ -0,0 +1,93 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: notifications.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const createNotification = `-- name: CreateNotification :exec
+INSERT INTO notifications (id, created_at, user_id, type, report_id, message)
+VALUES (gen_random_uuid(), CURRENT_TIMESTAMP, $1, $2, $3, $4)
+`
+
+type CreateNotificationParams struct {
+	UserID   uuid.UUID     `json:"user_id"`
+	Type     string        `json:"type"`
+	ReportID uuid.NullUUID `json:"report_id"`
+	Message  string        `json:"message"`
+}
+
+func (q *Queries) CreateNotification(ctx context.Context, arg CreateNotificationParams) error {
+	_, err := q.db.ExecContext(ctx, createNotification,
+		arg.UserID,
+		arg.Type,
+		arg.ReportID,
+		arg.Message,
+	)
+	return err
+}
+
+const getNotifications = `-- name: GetNotifications :many
+SELECT id, created_at, user_id, type, report_id, message, read_at FROM notifications
+WHERE user_id = $1
+    AND (NOT $2::BOOLEAN OR read_at IS NULL)
+ORDER BY created_at DESC
+LIMIT $3
+`
+
+type GetNotificationsParams struct {
+	UserID     uuid.UUID `json:"user_id"`
+	UnreadOnly bool      `json:"unread_only"`
+	RowLimit   int32     `json:"row_limit"`
+}
+
+func (q *Queries) GetNotifications(ctx context.Context, arg GetNotificationsParams) ([]Notification, error) {
+	rows, err := q.db.QueryContext(ctx, getNotifications, arg.UserID, arg.UnreadOnly, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Notification
+	for rows.Next() {
+		var i Notification
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UserID,
+			&i.Type,
+			&i.ReportID,
+			&i.Message,
+			&i.ReadAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markNotificationsRead = `-- name: MarkNotificationsRead :execrows
+UPDATE notifications
+SET read_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND read_at IS NULL
+`
+
+func (q *Queries) MarkNotificationsRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markNotificationsRead, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
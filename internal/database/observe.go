@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// QueryHook is called before a query runs with the sqlc query name (e.g.
+// "GetChirp"). the returned function is called with the query error once
+// the query has run. hooks are how the metrics and tracing packages see
+// into the database layer without sqlc-generated code knowing about them.
+type QueryHook func(ctx context.Context, name string) (context.Context, func(err error))
+
+type observedDB struct {
+	db    DBTX
+	hooks []QueryHook
+}
+
+// Observe wraps db so that every query passes through hooks. pass the
+// result to New in place of the raw *sql.DB or *sql.Tx.
+func Observe(db DBTX, hooks ...QueryHook) DBTX {
+	return &observedDB{db: db, hooks: hooks}
+}
+
+func (o *observedDB) start(ctx context.Context, query string) (context.Context, func(error)) {
+	name := QueryName(query)
+
+	dones := make([]func(error), 0, len(o.hooks))
+	for _, hook := range o.hooks {
+		var done func(error)
+		ctx, done = hook(ctx, name)
+		dones = append(dones, done)
+	}
+
+	return ctx, func(err error) {
+		// finish in reverse order, like deferred calls.
+		for i := len(dones) - 1; i >= 0; i-- {
+			dones[i](err)
+		}
+	}
+}
+
+func (o *observedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, done := o.start(ctx, query)
+	res, err := o.db.ExecContext(ctx, query, args...)
+	done(err)
+	return res, err
+}
+
+func (o *observedDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, done := o.start(ctx, query)
+	stmt, err := o.db.PrepareContext(ctx, query)
+	done(err)
+	return stmt, err
+}
+
+// QueryContext only covers running the query, not iterating over rows.
+func (o *observedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, done := o.start(ctx, query)
+	rows, err := o.db.QueryContext(ctx, query, args...)
+	done(err)
+	return rows, err
+}
+
+func (o *observedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, done := o.start(ctx, query)
+	row := o.db.QueryRowContext(ctx, query, args...)
+	done(row.Err())
+	return row
+}
+
+// QueryName extracts the sqlc query name from the "-- name: <Name> :<cmd>"
+// comment that sqlc keeps at the top of every generated query. queries
+// without that comment are reported as "unknown".
+func QueryName(query string) string {
+	const prefix = "-- name: "
+
+	if !strings.HasPrefix(query, prefix) {
+		return "unknown"
+	}
+
+	fields := strings.Fields(query[len(prefix):])
+	if len(fields) == 0 {
+		return "unknown"
+	}
+
+	return fields[0]
+}
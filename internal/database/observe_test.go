@@ -0,0 +1,25 @@
+package database
+
+import "testing"
+
+func TestQueryName(t *testing.T) {
+	cases := []struct {
+		query string
+		want  string
+	}{
+		{"-- name: GetChirp :one\nSELECT * FROM chirps WHERE id = $1", "GetChirp"},
+		{"-- name: DeleteChirp :exec\nDELETE FROM chirps", "DeleteChirp"},
+		{"-- name: ListChirps\nSELECT 1", "ListChirps"},
+		{"SELECT 1", "unknown"},
+		{"", "unknown"},
+		{"-- name: ", "unknown"},
+		{"  -- name: GetChirp :one", "unknown"},
+		{"-- GetChirp :one", "unknown"},
+	}
+
+	for _, c := range cases {
+		if got := QueryName(c.query); got != c.want {
+			t.Errorf("%q: expected %q, got %q\n", c.query, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: reports.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const claimReport = `-- name: ClaimReport :one
+UPDATE reports
+SET status = 'claimed', claimed_by = $1::UUID, claimed_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+    AND (status = 'open' OR (status = 'claimed' AND claimed_by = $1::UUID))
+RETURNING id, created_at, updated_at, reporter_id, reported_user_id, chirp_id, reason, details, status, claimed_by, claimed_at, resolution, resolution_note, resolved_at
+`
+
+type ClaimReportParams struct {
+	ModeratorID uuid.UUID `json:"moderator_id"`
+	ID          uuid.UUID `json:"id"`
+}
+
+// a report can be claimed while it's open, or claimed again by the same
+// moderator. anything else returns no rows.
+func (q *Queries) ClaimReport(ctx context.Context, arg ClaimReportParams) (Report, error) {
+	row := q.db.QueryRowContext(ctx, claimReport, arg.ModeratorID, arg.ID)
+	var i Report
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReporterID,
+		&i.ReportedUserID,
+		&i.ChirpID,
+		&i.Reason,
+		&i.Details,
+		&i.Status,
+		&i.ClaimedBy,
+		&i.ClaimedAt,
+		&i.Resolution,
+		&i.ResolutionNote,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
+
+const createAuditEntry = `-- name: CreateAuditEntry :exec
+INSERT INTO moderation_audit (id, created_at, moderator_id, report_id, action, note)
+VALUES (gen_random_uuid(), CURRENT_TIMESTAMP, $1, $2, $3, $4)
+`
+
+type CreateAuditEntryParams struct {
+	ModeratorID uuid.NullUUID `json:"moderator_id"`
+	ReportID    uuid.NullUUID `json:"report_id"`
+	Action      string        `json:"action"`
+	Note        string        `json:"note"`
+}
+
+func (q *Queries) CreateAuditEntry(ctx context.Context, arg CreateAuditEntryParams) error {
+	_, err := q.db.ExecContext(ctx, createAuditEntry,
+		arg.ModeratorID,
+		arg.ReportID,
+		arg.Action,
+		arg.Note,
+	)
+	return err
+}
+
+const createReport = `-- name: CreateReport :one
+INSERT INTO reports (
+    id, created_at, updated_at, reporter_id, reported_user_id, chirp_id, reason, details
+)
+VALUES (
+    gen_random_uuid(), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $1, $2, $3, $4, $5
+)
+RETURNING id, created_at, updated_at, reporter_id, reported_user_id, chirp_id, reason, details, status, claimed_by, claimed_at, resolution, resolution_note, resolved_at
+`
+
+type CreateReportParams struct {
+	ReporterID     uuid.UUID     `json:"reporter_id"`
+	ReportedUserID uuid.UUID     `json:"reported_user_id"`
+	ChirpID        uuid.NullUUID `json:"chirp_id"`
+	Reason         string        `json:"reason"`
+	Details        string        `json:"details"`
+}
+
+func (q *Queries) CreateReport(ctx context.Context, arg CreateReportParams) (Report, error) {
+	row := q.db.QueryRowContext(ctx, createReport,
+		arg.ReporterID,
+		arg.ReportedUserID,
+		arg.ChirpID,
+		arg.Reason,
+		arg.Details,
+	)
+	var i Report
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReporterID,
+		&i.ReportedUserID,
+		&i.ChirpID,
+		&i.Reason,
+		&i.Details,
+		&i.Status,
+		&i.ClaimedBy,
+		&i.ClaimedAt,
+		&i.Resolution,
+		&i.ResolutionNote,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
+
+const getAuditEntriesByReport = `-- name: GetAuditEntriesByReport :many
+SELECT moderation_audit.id, moderation_audit.created_at, moderation_audit.moderator_id, moderation_audit.report_id, moderation_audit.action, moderation_audit.note, users.handle AS moderator_handle
+FROM moderation_audit
+LEFT JOIN users ON users.id = moderation_audit.moderator_id
+WHERE moderation_audit.report_id = $1
+ORDER BY moderation_audit.created_at ASC
+`
+
+type GetAuditEntriesByReportRow struct {
+	ID              uuid.UUID      `json:"id"`
+	CreatedAt       time.Time      `json:"created_at"`
+	ModeratorID     uuid.NullUUID  `json:"moderator_id"`
+	ReportID        uuid.NullUUID  `json:"report_id"`
+	Action          string         `json:"action"`
+	Note            string         `json:"note"`
+	ModeratorHandle sql.NullString `json:"moderator_handle"`
+}
+
+func (q *Queries) GetAuditEntriesByReport(ctx context.Context, reportID uuid.NullUUID) ([]GetAuditEntriesByReportRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAuditEntriesByReport, reportID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAuditEntriesByReportRow
+	for rows.Next() {
+		var i GetAuditEntriesByReportRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.ModeratorID,
+			&i.ReportID,
+			&i.Action,
+			&i.Note,
+			&i.ModeratorHandle,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReport = `-- name: GetReport :one
+SELECT id, created_at, updated_at, reporter_id, reported_user_id, chirp_id, reason, details, status, claimed_by, claimed_at, resolution, resolution_note, resolved_at FROM reports
+WHERE id = $1
+`
+
+func (q *Queries) GetReport(ctx context.Context, id uuid.UUID) (Report, error) {
+	row := q.db.QueryRowContext(ctx, getReport, id)
+	var i Report
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReporterID,
+		&i.ReportedUserID,
+		&i.ChirpID,
+		&i.Reason,
+		&i.Details,
+		&i.Status,
+		&i.ClaimedBy,
+		&i.ClaimedAt,
+		&i.Resolution,
+		&i.ResolutionNote,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
+
+const hideChirp = `-- name: HideChirp :execrows
+UPDATE chirps
+SET hidden_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND hidden_at IS NULL
+`
+
+func (q *Queries) HideChirp(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, hideChirp, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listReportsByStatus = `-- name: ListReportsByStatus :many
+SELECT reports.id, reports.created_at, reports.updated_at, reports.reporter_id, reports.reported_user_id, reports.chirp_id, reports.reason, reports.details, reports.status, reports.claimed_by, reports.claimed_at, reports.resolution, reports.resolution_note, reports.resolved_at, chirps.body AS chirp_body, users.handle AS reported_handle
+FROM reports
+JOIN users ON users.id = reports.reported_user_id
+LEFT JOIN chirps ON chirps.id = reports.chirp_id
+WHERE reports.status = $1
+ORDER BY reports.created_at ASC
+LIMIT $2
+`
+
+type ListReportsByStatusParams struct {
+	Status string `json:"status"`
+	Limit  int32  `json:"limit"`
+}
+
+type ListReportsByStatusRow struct {
+	ID             uuid.UUID      `json:"id"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	ReporterID     uuid.UUID      `json:"reporter_id"`
+	ReportedUserID uuid.UUID      `json:"reported_user_id"`
+	ChirpID        uuid.NullUUID  `json:"chirp_id"`
+	Reason         string         `json:"reason"`
+	Details        string         `json:"details"`
+	Status         string         `json:"status"`
+	ClaimedBy      uuid.NullUUID  `json:"claimed_by"`
+	ClaimedAt      sql.NullTime   `json:"claimed_at"`
+	Resolution     sql.NullString `json:"resolution"`
+	ResolutionNote string         `json:"resolution_note"`
+	ResolvedAt     sql.NullTime   `json:"resolved_at"`
+	ChirpBody      sql.NullString `json:"chirp_body"`
+	ReportedHandle string         `json:"reported_handle"`
+}
+
+// the moderation queue, oldest first so nothing waits forever.
+func (q *Queries) ListReportsByStatus(ctx context.Context, arg ListReportsByStatusParams) ([]ListReportsByStatusRow, error) {
+	rows, err := q.db.QueryContext(ctx, listReportsByStatus, arg.Status, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListReportsByStatusRow
+	for rows.Next() {
+		var i ListReportsByStatusRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ReporterID,
+			&i.ReportedUserID,
+			&i.ChirpID,
+			&i.Reason,
+			&i.Details,
+			&i.Status,
+			&i.ClaimedBy,
+			&i.ClaimedAt,
+			&i.Resolution,
+			&i.ResolutionNote,
+			&i.ResolvedAt,
+			&i.ChirpBody,
+			&i.ReportedHandle,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resolveReport = `-- name: ResolveReport :one
+UPDATE reports
+SET status = 'resolved', resolution = $1::TEXT, resolution_note = $2,
+    resolved_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $3 AND status = 'claimed' AND claimed_by = $4::UUID
+RETURNING id, created_at, updated_at, reporter_id, reported_user_id, chirp_id, reason, details, status, claimed_by, claimed_at, resolution, resolution_note, resolved_at
+`
+
+type ResolveReportParams struct {
+	Resolution  string    `json:"resolution"`
+	Note        string    `json:"note"`
+	ID          uuid.UUID `json:"id"`
+	ModeratorID uuid.UUID `json:"moderator_id"`
+}
+
+// only the moderator who claimed a report can resolve it.
+func (q *Queries) ResolveReport(ctx context.Context, arg ResolveReportParams) (Report, error) {
+	row := q.db.QueryRowContext(ctx, resolveReport,
+		arg.Resolution,
+		arg.Note,
+		arg.ID,
+		arg.ModeratorID,
+	)
+	var i Report
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReporterID,
+		&i.ReportedUserID,
+		&i.ChirpID,
+		&i.Reason,
+		&i.Details,
+		&i.Status,
+		&i.ClaimedBy,
+		&i.ClaimedAt,
+		&i.Resolution,
+		&i.ResolutionNote,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,57 @@
+-- name: BookmarkChirp :exec
+-- bookmarking a chirp again moves it to folder_id, and keeps its place in
+-- the list.
+INSERT INTO bookmarks (user_id, chirp_id, created_at, folder_id)
+VALUES ($1, $2, CURRENT_TIMESTAMP, $3)
+ON CONFLICT (user_id, chirp_id) DO UPDATE SET folder_id = EXCLUDED.folder_id;
+
+-- name: UnbookmarkChirp :execrows
+DELETE FROM bookmarks
+WHERE user_id = $1 AND chirp_id = $2;
+
+-- name: GetBookmarks :many
+-- newest first, older than before when given, only in folder_id when given.
+-- chirps the user can't see anymore (hidden, blocked, author leaving) are
+-- left out but their bookmarks kept, in case they come back.
+SELECT bookmarks.created_at AS bookmarked_at,
+    bookmarks.folder_id,
+    sqlc.embed(chirps),
+    users.handle AS author_handle,
+    users.display_name AS author_display_name,
+    users.avatar_url AS author_avatar_url
+FROM bookmarks
+JOIN chirps ON chirps.id = bookmarks.chirp_id
+JOIN users ON users.id = chirps.user_id AND users.delete_after IS NULL
+WHERE bookmarks.user_id = sqlc.arg(user_id)
+    AND (sqlc.narg(folder_id)::UUID IS NULL OR bookmarks.folder_id = sqlc.narg(folder_id))
+    AND (sqlc.narg(before)::TIMESTAMPTZ IS NULL OR bookmarks.created_at < sqlc.narg(before))
+    AND chirps.hidden_at IS NULL AND chirps.status = 'published'
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = bookmarks.user_id)
+            OR (blocks.blocker_id = bookmarks.user_id AND blocks.blocked_id = chirps.user_id)
+    )
+ORDER BY bookmarks.created_at DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: CreateBookmarkFolder :one
+INSERT INTO bookmark_folders (id, created_at, user_id, name)
+VALUES (gen_random_uuid(), CURRENT_TIMESTAMP, $1, $2)
+RETURNING *;
+
+-- name: GetBookmarkFolder :one
+SELECT * FROM bookmark_folders
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetBookmarkFolders :many
+SELECT * FROM bookmark_folders
+WHERE user_id = $1
+ORDER BY LOWER(name);
+
+-- name: CountBookmarkFolders :one
+SELECT COUNT(*) FROM bookmark_folders
+WHERE user_id = $1;
+
+-- name: DeleteBookmarkFolder :execrows
+DELETE FROM bookmark_folders
+WHERE id = $1 AND user_id = $2;
\ No newline at end of file
@@ -1,9 +1,9 @@
 -- name: AddChirp :one
 INSERT INTO chirps (
-    id, created_at, updated_at, body, user_id
+    id, created_at, updated_at, body, user_id, quoted_chirp_id
 )
 VALUES (
-    gen_random_uuid(), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $1, $2
+    gen_random_uuid(), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $1, $2, $3
 )
 RETURNING *;
 
@@ -17,4 +17,175 @@ ORDER BY created_at ASC;
 
 -- name: DeleteChirp :exec
 DELETE FROM chirps
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: DeleteChirps :exec
+DELETE FROM chirps;
+
+-- name: GetChirpsByUser :many
+SELECT * FROM chirps
+WHERE user_id = $1
+ORDER BY created_at ASC;
+
+-- name: GetChirpWithAuthor :one
+-- viewer_id is the user asking, NULL when anonymous. chirps of users who
+-- blocked them, or whom they blocked, are left out.
+SELECT sqlc.embed(chirps),
+    users.handle AS author_handle,
+    users.display_name AS author_display_name,
+    users.avatar_url AS author_avatar_url
+FROM chirps
+JOIN users ON users.id = chirps.user_id AND users.delete_after IS NULL
+WHERE chirps.id = sqlc.arg(id) AND chirps.hidden_at IS NULL AND chirps.status = 'published'
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg(viewer_id))
+            OR (blocks.blocker_id = sqlc.narg(viewer_id) AND blocks.blocked_id = chirps.user_id)
+    );
+
+-- name: GetChirpsWithAuthors :many
+-- like GetChirpWithAuthor, and chirps of users muted by muter_id are left
+-- out as well. muter_id is separate so mutes can be ignored when the viewer
+-- asks for a muted user's chirps on purpose.
+SELECT sqlc.embed(chirps),
+    users.handle AS author_handle,
+    users.display_name AS author_display_name,
+    users.avatar_url AS author_avatar_url
+FROM chirps
+JOIN users ON users.id = chirps.user_id AND users.delete_after IS NULL
+WHERE chirps.hidden_at IS NULL AND chirps.status = 'published'
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg(viewer_id))
+            OR (blocks.blocker_id = sqlc.narg(viewer_id) AND blocks.blocked_id = chirps.user_id)
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM mutes
+        WHERE mutes.muter_id = sqlc.narg(muter_id) AND mutes.muted_id = chirps.user_id
+    )
+ORDER BY chirps.created_at ASC;
+
+-- name: GetChirpForUpdate :one
+-- locks the chirp until the end of the transaction, so concurrent edits
+-- each get their own revision.
+SELECT * FROM chirps
+WHERE id = $1
+FOR UPDATE;
+
+-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $2, updated_at = CURRENT_TIMESTAMP, edited_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateChirpRevision :exec
+INSERT INTO chirp_revisions (id, chirp_id, created_at, body)
+VALUES (gen_random_uuid(), $1, $2, $3);
+
+-- name: GetChirpRevisions :many
+SELECT created_at, body FROM chirp_revisions
+WHERE chirp_id = $1
+ORDER BY created_at DESC;
+
+-- name: ScheduleChirp :one
+INSERT INTO chirps (
+    id, created_at, updated_at, body, user_id, status, publish_at, quoted_chirp_id
+)
+VALUES (
+    gen_random_uuid(), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $1, $2, 'scheduled', $3, $4
+)
+RETURNING *;
+
+-- name: GetScheduledChirps :many
+SELECT * FROM chirps
+WHERE user_id = $1 AND status = 'scheduled'
+ORDER BY publish_at ASC;
+
+-- name: RescheduleChirp :one
+UPDATE chirps
+SET publish_at = $3, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND user_id = $2 AND status = 'scheduled'
+RETURNING *;
+
+-- name: CancelScheduledChirp :execrows
+DELETE FROM chirps
+WHERE id = $1 AND user_id = $2 AND status = 'scheduled';
+
+-- name: PublishDueChirps :many
+-- publishes up to batch_size chirps that are due. rows another replica is
+-- already publishing are skipped instead of waited for, and the status
+-- check in the UPDATE keeps a chirp from being published twice.
+UPDATE chirps
+SET status = 'published', created_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT id FROM chirps
+    WHERE status = 'scheduled' AND publish_at <= CURRENT_TIMESTAMP
+    ORDER BY publish_at
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+) AND status = 'scheduled'
+RETURNING *;
+
+-- name: GetQuotedChirps :many
+-- the chirps quoted by the chirps in a response, out of the given IDs, as
+-- GetChirpWithAuthor would return them to viewer_id.
+SELECT sqlc.embed(chirps),
+    users.handle AS author_handle,
+    users.display_name AS author_display_name,
+    users.avatar_url AS author_avatar_url
+FROM chirps
+JOIN users ON users.id = chirps.user_id AND users.delete_after IS NULL
+WHERE chirps.id = ANY(sqlc.arg(ids)::UUID[])
+    AND chirps.hidden_at IS NULL AND chirps.status = 'published'
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg(viewer_id))
+            OR (blocks.blocker_id = sqlc.narg(viewer_id) AND blocks.blocked_id = chirps.user_id)
+    );
+
+-- name: CountQuotes :many
+-- how many quotes each of the given chirps has that GetQuotes would list
+-- for viewer_id. chirps without any are left out.
+SELECT chirps.quoted_chirp_id::UUID AS chirp_id, COUNT(*) AS quote_count
+FROM chirps
+JOIN users ON users.id = chirps.user_id AND users.delete_after IS NULL
+WHERE chirps.quoted_chirp_id = ANY(sqlc.arg(ids)::UUID[])
+    AND chirps.hidden_at IS NULL AND chirps.status = 'published'
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg(viewer_id))
+            OR (blocks.blocker_id = sqlc.narg(viewer_id) AND blocks.blocked_id = chirps.user_id)
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM mutes
+        WHERE mutes.muter_id = sqlc.narg(viewer_id) AND mutes.muted_id = chirps.user_id
+    )
+GROUP BY chirps.quoted_chirp_id;
+
+-- name: GetQuotes :many
+-- the quotes of a chirp, newest first, as GetChirpsWithAuthors would return
+-- them. older than before when given; before_id breaks ties between quotes
+-- published at the same time when paging through them.
+SELECT sqlc.embed(chirps),
+    users.handle AS author_handle,
+    users.display_name AS author_display_name,
+    users.avatar_url AS author_avatar_url
+FROM chirps
+JOIN users ON users.id = chirps.user_id AND users.delete_after IS NULL
+WHERE chirps.quoted_chirp_id = sqlc.arg(quoted_chirp_id)
+    AND (
+        sqlc.narg(before)::TIMESTAMPTZ IS NULL
+        OR (chirps.created_at, chirps.id) < (sqlc.narg(before), COALESCE(sqlc.narg(before_id)::UUID, '00000000-0000-0000-0000-000000000000'))
+    )
+    AND chirps.hidden_at IS NULL AND chirps.status = 'published'
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg(viewer_id))
+            OR (blocks.blocker_id = sqlc.narg(viewer_id) AND blocks.blocked_id = chirps.user_id)
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM mutes
+        WHERE mutes.muter_id = sqlc.narg(viewer_id) AND mutes.muted_id = chirps.user_id
+    )
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(row_limit);
\ No newline at end of file
@@ -0,0 +1,29 @@
+-- name: ListFilterRules :many
+SELECT * FROM filter_rules
+ORDER BY word;
+
+-- name: CreateFilterRule :one
+INSERT INTO filter_rules (id, created_at, updated_at, word, action)
+VALUES (gen_random_uuid(), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $1, $2)
+RETURNING *;
+
+-- name: UpdateFilterRule :one
+UPDATE filter_rules
+SET action = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteFilterRule :execrows
+DELETE FROM filter_rules
+WHERE id = $1;
+
+-- name: CreateFilterFlag :exec
+INSERT INTO filter_flags (id, created_at, chirp_id, rule_id, matched)
+VALUES (gen_random_uuid(), CURRENT_TIMESTAMP, $1, $2, $3);
+
+-- name: ListFilterFlags :many
+SELECT filter_flags.*, chirps.body AS chirp_body, chirps.user_id AS chirp_user_id
+FROM filter_flags
+JOIN chirps ON chirps.id = filter_flags.chirp_id
+ORDER BY filter_flags.created_at DESC
+LIMIT $1;
\ No newline at end of file
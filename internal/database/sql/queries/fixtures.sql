@@ -0,0 +1,13 @@
+-- name: InsertFixtureUser :one
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio)
+VALUES (
+    $1, $2, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING *;
+
+-- name: InsertFixtureChirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+VALUES (
+    $1, $2, $2, $3, $4
+)
+RETURNING *;
\ No newline at end of file
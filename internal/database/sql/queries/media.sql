@@ -0,0 +1,83 @@
+-- name: CreateMedia :one
+INSERT INTO media (
+    id, created_at, user_id, content_type, size_bytes, width, height,
+    storage_key, thumbnail_key, thumbnail_content_type
+)
+VALUES (
+    $1, CURRENT_TIMESTAMP, $2, $3, $4, $5, $6, $7, $8, $9
+)
+RETURNING *;
+
+-- name: GetMedia :one
+SELECT * FROM media
+WHERE id = $1;
+
+-- name: GetVisibleMedia :one
+-- media as viewer_id (NULL when anonymous) may see it: on a chirp they can
+-- see, like GetChirpWithAuthor, or not attached yet and their own upload.
+-- authors can see the media of their own scheduled chirps.
+SELECT media.* FROM media
+JOIN users ON users.id = media.user_id AND users.delete_after IS NULL
+LEFT JOIN chirp_media ON chirp_media.media_id = media.id
+LEFT JOIN chirps ON chirps.id = chirp_media.chirp_id
+WHERE media.id = sqlc.arg(id)
+    AND (
+        (chirps.id IS NULL AND media.user_id = sqlc.narg(viewer_id))
+        OR (
+            chirps.hidden_at IS NULL
+            AND (chirps.status = 'published' OR chirps.user_id = sqlc.narg(viewer_id))
+            AND NOT EXISTS (
+                SELECT 1 FROM blocks
+                WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg(viewer_id))
+                    OR (blocks.blocker_id = sqlc.narg(viewer_id) AND blocks.blocked_id = chirps.user_id)
+            )
+        )
+    );
+
+-- name: GetUnattachedMedia :many
+-- media owned by the user that isn't on a chirp yet, out of the given IDs.
+SELECT media.* FROM media
+LEFT JOIN chirp_media ON chirp_media.media_id = media.id
+WHERE media.id = ANY(sqlc.arg(ids)::UUID[])
+    AND media.user_id = sqlc.arg(user_id)
+    AND chirp_media.media_id IS NULL;
+
+-- name: AttachMedia :exec
+INSERT INTO chirp_media (chirp_id, media_id, position, alt_text)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetChirpMedia :many
+SELECT chirp_media.chirp_id, chirp_media.position, chirp_media.alt_text, sqlc.embed(media)
+FROM chirp_media
+JOIN media ON media.id = chirp_media.media_id
+WHERE chirp_media.chirp_id = ANY(sqlc.arg(chirp_ids)::UUID[])
+ORDER BY chirp_media.chirp_id, chirp_media.position;
+
+-- name: DeleteAbandonedMedia :execrows
+-- uploads that were never attached, or whose chirp is gone, and that no
+-- draft refers to, once they're older than created_before. deleting them
+-- queues their blobs for deletion.
+DELETE FROM media
+WHERE media.created_at < sqlc.arg(created_before)
+    AND NOT EXISTS (SELECT 1 FROM chirp_media WHERE chirp_media.media_id = media.id)
+    AND NOT EXISTS (
+        SELECT 1 FROM drafts
+        WHERE drafts.user_id = media.user_id
+            AND drafts.media @> jsonb_build_array(jsonb_build_object('id', media.id::TEXT))
+    );
+
+-- name: QueueMediaBlobDeletion :exec
+-- queues a blob that never got a media row, e.g. from a failed upload, for
+-- chirpy.CleanUpMedia.
+INSERT INTO media_blob_deletions (storage_key, created_at)
+VALUES ($1, CURRENT_TIMESTAMP)
+ON CONFLICT DO NOTHING;
+
+-- name: GetMediaBlobDeletions :many
+SELECT storage_key FROM media_blob_deletions
+ORDER BY created_at
+LIMIT $1;
+
+-- name: DeleteMediaBlobDeletion :exec
+DELETE FROM media_blob_deletions
+WHERE storage_key = $1;
\ No newline at end of file
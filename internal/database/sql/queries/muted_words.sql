@@ -0,0 +1,23 @@
+-- name: CreateMutedWord :one
+INSERT INTO muted_words (id, created_at, user_id, pattern, mode, expires_at)
+VALUES (gen_random_uuid(), CURRENT_TIMESTAMP, $1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetActiveMutedWords :many
+SELECT * FROM muted_words
+WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+ORDER BY created_at DESC;
+
+-- name: CountActiveMutedWords :one
+SELECT COUNT(*) FROM muted_words
+WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP);
+
+-- name: DeleteMutedWord :execrows
+DELETE FROM muted_words
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteExpiredMutedWords :execrows
+-- expired entries stop applying on their own; this only keeps them from
+-- piling up and blocking the same pattern from being muted again.
+DELETE FROM muted_words
+WHERE user_id = $1 AND expires_at <= CURRENT_TIMESTAMP;
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- name: CreateNotification :exec
+INSERT INTO notifications (id, created_at, user_id, type, report_id, message)
+VALUES (gen_random_uuid(), CURRENT_TIMESTAMP, $1, $2, $3, $4);
+
+-- name: GetNotifications :many
+SELECT * FROM notifications
+WHERE user_id = sqlc.arg(user_id)
+    AND (NOT sqlc.arg(unread_only)::BOOLEAN OR read_at IS NULL)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: MarkNotificationsRead :execrows
+UPDATE notifications
+SET read_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND read_at IS NULL;
\ No newline at end of file
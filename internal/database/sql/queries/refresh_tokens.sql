@@ -14,4 +14,28 @@ WHERE token = $1;
 -- name: UpdateRefreshToken :exec
 UPDATE refresh_tokens
 SET revoked_at = $1, updated_at = $2
-WHERE user_id = $3;
\ No newline at end of file
+WHERE user_id = $3;
+
+-- name: DeleteRefreshTokens :exec
+DELETE FROM refresh_tokens;
+
+-- name: RevokeRefreshToken :execrows
+UPDATE refresh_tokens
+SET revoked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE token = $1 AND revoked_at IS NULL;
+
+-- name: RevokeUserRefreshTokens :execrows
+UPDATE refresh_tokens
+SET revoked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeAllRefreshTokens :execrows
+UPDATE refresh_tokens
+SET revoked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE revoked_at IS NULL;
+
+-- name: GetRefreshTokensByUser :many
+-- for exports; the token itself is a credential and is left out.
+SELECT created_at, updated_at, expires_at, revoked_at FROM refresh_tokens
+WHERE user_id = $1
+ORDER BY created_at ASC;
\ No newline at end of file
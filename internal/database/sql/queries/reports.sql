@@ -0,0 +1,56 @@
+-- name: CreateReport :one
+INSERT INTO reports (
+    id, created_at, updated_at, reporter_id, reported_user_id, chirp_id, reason, details
+)
+VALUES (
+    gen_random_uuid(), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetReport :one
+SELECT * FROM reports
+WHERE id = $1;
+
+-- name: ListReportsByStatus :many
+-- the moderation queue, oldest first so nothing waits forever.
+SELECT reports.*, chirps.body AS chirp_body, users.handle AS reported_handle
+FROM reports
+JOIN users ON users.id = reports.reported_user_id
+LEFT JOIN chirps ON chirps.id = reports.chirp_id
+WHERE reports.status = $1
+ORDER BY reports.created_at ASC
+LIMIT $2;
+
+-- name: ClaimReport :one
+-- a report can be claimed while it's open, or claimed again by the same
+-- moderator. anything else returns no rows.
+UPDATE reports
+SET status = 'claimed', claimed_by = sqlc.arg(moderator_id)::UUID, claimed_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
+    AND (status = 'open' OR (status = 'claimed' AND claimed_by = sqlc.arg(moderator_id)::UUID))
+RETURNING *;
+
+-- name: ResolveReport :one
+-- only the moderator who claimed a report can resolve it.
+UPDATE reports
+SET status = 'resolved', resolution = sqlc.arg(resolution)::TEXT, resolution_note = sqlc.arg(note),
+    resolved_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND status = 'claimed' AND claimed_by = sqlc.arg(moderator_id)::UUID
+RETURNING *;
+
+-- name: HideChirp :execrows
+UPDATE chirps
+SET hidden_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND hidden_at IS NULL;
+
+-- name: CreateAuditEntry :exec
+INSERT INTO moderation_audit (id, created_at, moderator_id, report_id, action, note)
+VALUES (gen_random_uuid(), CURRENT_TIMESTAMP, $1, $2, $3, $4);
+
+-- name: GetAuditEntriesByReport :many
+SELECT moderation_audit.*, users.handle AS moderator_handle
+FROM moderation_audit
+LEFT JOIN users ON users.id = moderation_audit.moderator_id
+WHERE moderation_audit.report_id = $1
+ORDER BY moderation_audit.created_at ASC;
\ No newline at end of file
@@ -1,7 +1,7 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, handle, display_name)
 VALUES (
-    gen_random_uuid(), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $1, $2
+    gen_random_uuid(), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $1, $2, $3, $4
 )
 RETURNING *;
 
@@ -20,6 +20,80 @@ RETURNING *;
 
 -- name: UpgradeUser :one
 UPDATE users
-SET is_chirpy_red = TRUE
+SET is_chirpy_red = TRUE, updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = $1;
+
+-- name: SetUserRole :one
+UPDATE users
+SET role = $1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+RETURNING *;
+
+-- name: SuspendUser :one
+UPDATE users
+SET suspended_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: UnsuspendUser :one
+UPDATE users
+SET suspended_at = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: PatchUser :one
+-- only the given fields are changed. if_updated_at makes the update
+-- conditional for If-Match; no row is returned if the user changed since.
+UPDATE users
+SET email = COALESCE(sqlc.narg('email'), email),
+    hashed_password = COALESCE(sqlc.narg('hashed_password'), hashed_password),
+    handle = COALESCE(sqlc.narg('handle'), handle),
+    display_name = COALESCE(sqlc.narg('display_name'), display_name),
+    bio = COALESCE(sqlc.narg('bio'), bio),
+    location = COALESCE(sqlc.narg('location'), location),
+    website = COALESCE(sqlc.narg('website'), website),
+    avatar_url = COALESCE(sqlc.narg('avatar_url'), avatar_url),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id')
+    AND (sqlc.narg('if_updated_at')::TIMESTAMPTZ IS NULL OR updated_at = sqlc.narg('if_updated_at'))
+RETURNING *;
+
+-- name: GetProfileByHandle :one
+-- viewer_id is the user asking, NULL when anonymous or when blocks don't
+-- matter. users who blocked the viewer, or whom the viewer blocked, are
+-- not found.
+SELECT sqlc.embed(users),
+    (SELECT COUNT(*) FROM chirps WHERE chirps.user_id = users.id AND chirps.hidden_at IS NULL AND chirps.status = 'published') AS chirp_count
+FROM users
+WHERE LOWER(handle) = LOWER(sqlc.arg(handle)) AND delete_after IS NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = users.id AND blocks.blocked_id = sqlc.narg(viewer_id))
+            OR (blocks.blocker_id = sqlc.narg(viewer_id) AND blocks.blocked_id = users.id)
+    );
+
+-- name: ScheduleUserDeletion :one
+UPDATE users
+SET delete_after = $1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+RETURNING *;
+
+-- name: CancelUserDeletion :one
+-- only within the grace period; no rows once it's over.
+UPDATE users
+SET delete_after = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND delete_after > CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1;
+
+-- name: PurgeDeletedUsers :execrows
+DELETE FROM users
+WHERE delete_after <= CURRENT_TIMESTAMP;
\ No newline at end of file
@@ -0,0 +1,28 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN handle TEXT,
+ADD COLUMN display_name TEXT NOT NULL DEFAULT '',
+ADD COLUMN bio TEXT NOT NULL DEFAULT '',
+ADD COLUMN location TEXT NOT NULL DEFAULT '',
+ADD COLUMN website TEXT NOT NULL DEFAULT '',
+ADD COLUMN avatar_url TEXT NOT NULL DEFAULT '';
+
+-- existing users get a placeholder handle they can change later.
+UPDATE users SET handle = 'user_' || LEFT(REPLACE(id::TEXT, '-', ''), 12);
+
+ALTER TABLE users ALTER COLUMN handle SET NOT NULL;
+
+-- handles are unique regardless of case, but keep the case they were
+-- registered with for display.
+CREATE UNIQUE INDEX users_handle_key ON users (LOWER(handle));
+
+-- +goose Down
+DROP INDEX users_handle_key;
+
+ALTER TABLE users
+DROP COLUMN avatar_url,
+DROP COLUMN website,
+DROP COLUMN location,
+DROP COLUMN bio,
+DROP COLUMN display_name,
+DROP COLUMN handle;
\ No newline at end of file
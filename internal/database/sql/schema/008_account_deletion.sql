@@ -0,0 +1,15 @@
+-- +goose Up
+-- set while an account is waiting to be deleted. logging in before then
+-- clears it, after that the purge worker deletes the user and, through
+-- ON DELETE CASCADE, everything they own.
+ALTER TABLE users
+ADD COLUMN delete_after TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX users_delete_after_idx ON users (delete_after)
+WHERE delete_after IS NOT NULL;
+
+-- +goose Down
+DROP INDEX users_delete_after_idx;
+
+ALTER TABLE users
+DROP COLUMN delete_after;
\ No newline at end of file
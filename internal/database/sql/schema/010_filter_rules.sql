@@ -0,0 +1,36 @@
+-- +goose Up
+-- words are stored normalized (see the filter package), so the unique
+-- index catches "Kerfuffle" and "k3rfuffle" being added twice.
+CREATE TABLE filter_rules (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    word TEXT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('mask', 'reject', 'flag')),
+    CONSTRAINT filter_rules_word_key UNIQUE (word)
+);
+
+-- the words the hardcoded filter used to mask.
+INSERT INTO filter_rules (id, created_at, updated_at, word, action)
+VALUES
+    (gen_random_uuid(), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'kerfuffle', 'mask'),
+    (gen_random_uuid(), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'sharbert', 'mask'),
+    (gen_random_uuid(), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'fornax', 'mask');
+
+-- chirps that matched a flag rule, waiting for a moderator. the rule may be
+-- deleted since, so the matched word is kept as written.
+CREATE TABLE filter_flags (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    chirp_id UUID NOT NULL,
+    rule_id UUID,
+    matched TEXT NOT NULL,
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE,
+    FOREIGN KEY (rule_id) REFERENCES filter_rules(id) ON DELETE SET NULL
+);
+
+CREATE INDEX filter_flags_created_at_idx ON filter_flags (created_at);
+
+-- +goose Down
+DROP TABLE filter_flags;
+DROP TABLE filter_rules;
\ No newline at end of file
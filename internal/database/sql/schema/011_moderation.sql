@@ -0,0 +1,77 @@
+-- +goose Up
+-- hidden chirps stay in the database for the audit trail and their author,
+-- but are left out of every public read.
+ALTER TABLE chirps
+ADD COLUMN hidden_at TIMESTAMP WITH TIME ZONE;
+
+-- a report is about a chirp, or about a user when chirp_id is NULL. the
+-- reported user is kept either way so reports survive the chirp being
+-- deleted.
+CREATE TABLE reports (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    reporter_id UUID NOT NULL,
+    reported_user_id UUID NOT NULL,
+    chirp_id UUID,
+    reason TEXT NOT NULL
+        CHECK (reason IN ('spam', 'harassment', 'hate', 'violence', 'sexual', 'impersonation', 'other')),
+    details TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'claimed', 'resolved')),
+    claimed_by UUID,
+    claimed_at TIMESTAMP WITH TIME ZONE,
+    resolution TEXT
+        CHECK (resolution IN ('dismiss', 'hide_chirp', 'suspend_author')),
+    resolution_note TEXT NOT NULL DEFAULT '',
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (reported_user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE SET NULL,
+    FOREIGN KEY (claimed_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX reports_status_created_at_idx ON reports (status, created_at);
+
+-- one open report per reporter and chirp (or user), so the queue can't be
+-- flooded by the same person.
+CREATE UNIQUE INDEX reports_open_chirp_key ON reports (reporter_id, chirp_id)
+WHERE status <> 'resolved' AND chirp_id IS NOT NULL;
+CREATE UNIQUE INDEX reports_open_user_key ON reports (reporter_id, reported_user_id)
+WHERE status <> 'resolved' AND chirp_id IS NULL;
+
+-- every moderator action, kept even after the moderator or the report is
+-- gone.
+CREATE TABLE moderation_audit (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    moderator_id UUID,
+    report_id UUID,
+    action TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (moderator_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (report_id) REFERENCES reports(id) ON DELETE SET NULL
+);
+
+CREATE INDEX moderation_audit_report_id_idx ON moderation_audit (report_id);
+
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    user_id UUID NOT NULL,
+    type TEXT NOT NULL,
+    report_id UUID,
+    message TEXT NOT NULL,
+    read_at TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (report_id) REFERENCES reports(id) ON DELETE SET NULL
+);
+
+CREATE INDEX notifications_user_id_created_at_idx ON notifications (user_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE notifications;
+DROP TABLE moderation_audit;
+DROP TABLE reports;
+ALTER TABLE chirps
+DROP COLUMN hidden_at;
\ No newline at end of file
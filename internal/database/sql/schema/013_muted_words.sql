@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE muted_words (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    user_id UUID NOT NULL,
+    pattern TEXT NOT NULL,
+    mode TEXT NOT NULL CHECK (mode IN ('word', 'regex')),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX muted_words_user_pattern_key ON muted_words (user_id, mode, LOWER(pattern));
+
+-- +goose Down
+DROP TABLE muted_words;
\ No newline at end of file
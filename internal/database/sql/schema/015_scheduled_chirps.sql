@@ -0,0 +1,17 @@
+-- +goose Up
+-- scheduled chirps wait in the table until publish_at, hidden from every
+-- public read like hidden ones. publishing sets status and moves
+-- created_at to the time it went out, so the chirp sorts where it appeared.
+ALTER TABLE chirps
+ADD COLUMN status TEXT NOT NULL DEFAULT 'published'
+    CHECK (status IN ('scheduled', 'published')),
+ADD COLUMN publish_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX chirps_scheduled_publish_at_idx ON chirps (publish_at)
+WHERE status = 'scheduled';
+
+-- +goose Down
+DROP INDEX chirps_scheduled_publish_at_idx;
+ALTER TABLE chirps
+DROP COLUMN publish_at,
+DROP COLUMN status;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- +goose Up
+-- drafts are unfinished chirps, saved as they are and only checked when
+-- published. media holds the same [{"id", "alt_text"}] list as a new chirp;
+-- uploads are only attached on publish.
+CREATE TABLE drafts (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    user_id UUID NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    media JSONB NOT NULL DEFAULT '[]',
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX drafts_user_id_updated_at_idx ON drafts (user_id, updated_at DESC);
+
+-- +goose Down
+DROP TABLE drafts;
\ No newline at end of file
@@ -0,0 +1,32 @@
+-- +goose Up
+-- bookmarks are private to the user who made them. folders are optional;
+-- deleting one keeps its bookmarks, outside any folder.
+CREATE TABLE bookmark_folders (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    user_id UUID NOT NULL,
+    name TEXT NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX bookmark_folders_user_name_key ON bookmark_folders (user_id, LOWER(name));
+
+-- deleting a chirp deletes its bookmarks with it.
+CREATE TABLE bookmarks (
+    user_id UUID NOT NULL,
+    chirp_id UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    folder_id UUID,
+    PRIMARY KEY (user_id, chirp_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE,
+    FOREIGN KEY (folder_id) REFERENCES bookmark_folders(id) ON DELETE SET NULL
+);
+
+CREATE INDEX bookmarks_user_id_created_at_idx ON bookmarks (user_id, created_at DESC);
+CREATE INDEX bookmarks_chirp_id_idx ON bookmarks (chirp_id);
+CREATE INDEX bookmarks_folder_id_idx ON bookmarks (folder_id);
+
+-- +goose Down
+DROP TABLE bookmarks;
+DROP TABLE bookmark_folders;
\ No newline at end of file
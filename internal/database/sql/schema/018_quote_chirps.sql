@@ -0,0 +1,12 @@
+-- +goose Up
+-- a quote chirp embeds another chirp. when the quoted chirp is deleted the
+-- quote stays, with just its own body.
+ALTER TABLE chirps
+ADD COLUMN quoted_chirp_id UUID REFERENCES chirps(id) ON DELETE SET NULL;
+
+CREATE INDEX chirps_quoted_chirp_id_idx ON chirps (quoted_chirp_id, created_at DESC)
+WHERE quoted_chirp_id IS NOT NULL;
+
+-- +goose Down
+ALTER TABLE chirps
+DROP COLUMN quoted_chirp_id;
\ No newline at end of file
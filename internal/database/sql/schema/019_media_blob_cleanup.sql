@@ -0,0 +1,29 @@
+-- +goose Up
+-- media rows go away in many ways (account purges, ON DELETE CASCADE,
+-- resets), and none of them can delete the blobs in the store. a trigger
+-- queues the keys of every deleted media row here, and
+-- chirpy.CleanUpMedia deletes the blobs and then the queue entries.
+CREATE TABLE media_blob_deletions (
+    storage_key TEXT PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- +goose StatementBegin
+CREATE FUNCTION queue_media_blob_deletion() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO media_blob_deletions (storage_key, created_at)
+    VALUES (OLD.storage_key, CURRENT_TIMESTAMP), (OLD.thumbnail_key, CURRENT_TIMESTAMP)
+    ON CONFLICT DO NOTHING;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER media_queue_blob_deletion
+AFTER DELETE ON media
+FOR EACH ROW EXECUTE FUNCTION queue_media_blob_deletion();
+
+-- +goose Down
+DROP TRIGGER media_queue_blob_deletion ON media;
+DROP FUNCTION queue_media_blob_deletion();
+DROP TABLE media_blob_deletions;
\ No newline at end of file
@@ -0,0 +1,13 @@
+-- +goose Up
+-- quotes keep the ID of the chirp they quote after it's deleted, so
+-- clients can tell a quote of a deleted chirp from a plain chirp.
+ALTER TABLE chirps
+DROP CONSTRAINT chirps_quoted_chirp_id_fkey;
+
+-- +goose Down
+UPDATE chirps SET quoted_chirp_id = NULL
+WHERE quoted_chirp_id IS NOT NULL
+    AND NOT EXISTS (SELECT 1 FROM chirps quoted WHERE quoted.id = chirps.quoted_chirp_id);
+ALTER TABLE chirps
+ADD CONSTRAINT chirps_quoted_chirp_id_fkey
+FOREIGN KEY (quoted_chirp_id) REFERENCES chirps(id) ON DELETE SET NULL;
\ No newline at end of file
@@ -0,0 +1,9 @@
+package schema
+
+import "embed"
+
+// FS holds every goose migration in this directory, so the server binary
+// can migrate the database without the goose CLI or the source tree.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is read from incoming requests and echoed back on every
+// response so that clients, proxies and our logs agree on the same ID.
+const RequestIDHeader = "X-Request-ID"
+
+type ctxKey struct{}
+
+// requestInfo is the per-request state shared between the logging
+// middleware and handlers. handlers only learn who the user is after
+// validating the access token, so the middleware hands them a pointer
+// to fill in.
+type requestInfo struct {
+	logger    *slog.Logger
+	requestID string
+
+	mu     sync.Mutex
+	userID uuid.UUID
+}
+
+// New returns a JSON logger writing to w.
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// NewContext returns a copy of ctx carrying logger and requestID.
+func NewContext(ctx context.Context, logger *slog.Logger, requestID string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &requestInfo{
+		logger:    logger,
+		requestID: requestID,
+	})
+}
+
+// FromContext returns the request-scoped logger, or slog.Default() outside
+// of a request. the logger includes the current user ID once a handler has
+// called SetUserID.
+func FromContext(ctx context.Context) *slog.Logger {
+	info, ok := ctx.Value(ctxKey{}).(*requestInfo)
+	if !ok {
+		return slog.Default()
+	}
+
+	if userID := info.UserID(); userID != uuid.Nil {
+		return info.logger.With("user_id", userID)
+	}
+
+	return info.logger
+}
+
+// RequestID returns the request ID stored in ctx, if any.
+func RequestID(ctx context.Context) string {
+	info, ok := ctx.Value(ctxKey{}).(*requestInfo)
+	if !ok {
+		return ""
+	}
+
+	return info.requestID
+}
+
+// SetUserID records the authenticated user for the rest of the request.
+func SetUserID(ctx context.Context, userID uuid.UUID) {
+	info, ok := ctx.Value(ctxKey{}).(*requestInfo)
+	if !ok {
+		return
+	}
+
+	info.mu.Lock()
+	info.userID = userID
+	info.mu.Unlock()
+}
+
+// UserID returns the user set with SetUserID, or uuid.Nil.
+func UserID(ctx context.Context) uuid.UUID {
+	info, ok := ctx.Value(ctxKey{}).(*requestInfo)
+	if !ok {
+		return uuid.Nil
+	}
+
+	return info.UserID()
+}
+
+func (info *requestInfo) UserID() uuid.UUID {
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	return info.userID
+}
+
+// ValidRequestID reports whether a client supplied request ID is safe to
+// reuse. anything else is replaced with a fresh ID.
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
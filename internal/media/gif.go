@@ -0,0 +1,66 @@
+package media
+
+import "errors"
+
+var errBadGIF = errors.New("malformed GIF")
+
+// gifFrames counts the frames of a GIF without decoding them, by walking
+// its blocks. image.DecodeConfig only reads the logical screen size, and
+// every frame decodes to a full paletted image of up to that size.
+func gifFrames(data []byte) (int, error) {
+	// header and logical screen descriptor, then the global color table.
+	if len(data) < 13 {
+		return 0, errBadGIF
+	}
+	i := 13
+	if data[10]&0x80 != 0 {
+		i += 3 << (data[10]&0x07 + 1)
+	}
+
+	frames := 0
+	for i < len(data) {
+		switch data[i] {
+		case 0x21: // extension: label, then sub-blocks.
+			var err error
+			if i, err = skipSubBlocks(data, i+2); err != nil {
+				return 0, err
+			}
+		case 0x2C: // image descriptor, local color table, LZW code size, sub-blocks.
+			if i+10 > len(data) {
+				return 0, errBadGIF
+			}
+			flags := data[i+9]
+			i += 10
+			if flags&0x80 != 0 {
+				i += 3 << (flags&0x07 + 1)
+			}
+			var err error
+			if i, err = skipSubBlocks(data, i+1); err != nil {
+				return 0, err
+			}
+			frames++
+		case 0x3B: // trailer.
+			return frames, nil
+		default:
+			return 0, errBadGIF
+		}
+	}
+
+	// gif.DecodeAll copes without a trailer, so we do too.
+	return frames, nil
+}
+
+// skipSubBlocks returns the index after the sub-blocks starting at i,
+// which end with an empty one.
+func skipSubBlocks(data []byte, i int) (int, error) {
+	for {
+		if i >= len(data) {
+			return 0, errBadGIF
+		}
+		n := int(data[i])
+		i += 1 + n
+		if n == 0 {
+			return i, nil
+		}
+	}
+}
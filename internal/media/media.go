@@ -0,0 +1,187 @@
+// Package media turns uploaded images into what we store: re-encoded
+// without metadata (EXIF, with GPS coordinates and camera serial numbers,
+// mostly) and with a thumbnail.
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+const (
+	// MaxDimension and MaxPixels keep a small file from decoding into a
+	// huge image (a "decompression bomb"). for GIFs, MaxPixels covers
+	// every frame together.
+	MaxDimension = 8192
+	MaxPixels    = 40_000_000
+
+	// ThumbnailSize is the bounding box thumbnails are scaled down to fit.
+	ThumbnailSize = 320
+
+	jpegQuality = 90
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported media type")
+	ErrTooLarge        = errors.New("image dimensions too large")
+)
+
+// ContentTypes are the types uploads may have, as sniffed from their
+// content; what the client claims doesn't matter.
+var ContentTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// Image is a processed upload.
+type Image struct {
+	ContentType string
+	Data        []byte
+	Width       int
+	Height      int
+
+	ThumbnailContentType string
+	Thumbnail            []byte
+}
+
+// Process sniffs data, decodes it and re-encodes it. the output never has
+// metadata: only pixels make it through a decode/encode round trip. JPEG
+// orientation is applied to the pixels first, since dropping the EXIF
+// orientation tag would otherwise show photos sideways. WebP is re-encoded
+// as PNG, since there's no WebP encoder in the standard library.
+func Process(data []byte) (*Image, error) {
+	contentType := http.DetectContentType(data)
+
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	var cfg image.Config
+	var err error
+	if contentType == "image/webp" {
+		cfg, err = webp.DecodeConfig(bytes.NewReader(data))
+	} else {
+		cfg, _, err = image.DecodeConfig(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+	if cfg.Width > MaxDimension || cfg.Height > MaxDimension || cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+	if contentType == "image/gif" {
+		// a few bytes per frame can still each decode to a full screen.
+		frames, err := gifFrames(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+		}
+		if frames == 0 {
+			return nil, fmt.Errorf("%w: GIF without frames", ErrUnsupportedType)
+		}
+		if frames*cfg.Width*cfg.Height > MaxPixels {
+			return nil, ErrTooLarge
+		}
+	}
+
+	out := &Image{ContentType: contentType}
+	var first image.Image
+	var buf bytes.Buffer
+
+	switch contentType {
+	case "image/jpeg":
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+		}
+		first = orient(img, exifOrientation(data))
+		err = jpeg.Encode(&buf, first, &jpeg.Options{Quality: jpegQuality})
+		if err != nil {
+			return nil, err
+		}
+
+	case "image/png":
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+		}
+		first = img
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+
+	case "image/gif":
+		// keep every frame, so animations still move.
+		anim, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+		}
+		first = anim.Image[0]
+		if err := gif.EncodeAll(&buf, &gif.GIF{
+			Image:     anim.Image,
+			Delay:     anim.Delay,
+			LoopCount: anim.LoopCount,
+			Disposal:  anim.Disposal,
+			Config:    anim.Config,
+		}); err != nil {
+			return nil, err
+		}
+
+	case "image/webp":
+		img, err := webp.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+		}
+		first = img
+		out.ContentType = "image/png"
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+	}
+
+	out.Data = buf.Bytes()
+	out.Width, out.Height = first.Bounds().Dx(), first.Bounds().Dy()
+	if contentType == "image/gif" {
+		// frames can be smaller than the GIF, and offset inside it.
+		out.Width, out.Height = cfg.Width, cfg.Height
+	}
+
+	out.Thumbnail, out.ThumbnailContentType, err = thumbnail(first, out.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// thumbnail scales img down to fit ThumbnailSize, never up. photos become
+// JPEGs, everything else PNG to keep transparency.
+func thumbnail(img image.Image, contentType string) ([]byte, string, error) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > ThumbnailSize || h > ThumbnailSize {
+		if w >= h {
+			w, h = ThumbnailSize, max(1, h*ThumbnailSize/w)
+		} else {
+			w, h = max(1, w*ThumbnailSize/h), ThumbnailSize
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if contentType == "image/jpeg" {
+		err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality})
+		return buf.Bytes(), "image/jpeg", err
+	}
+	err := png.Encode(&buf, dst)
+	return buf.Bytes(), "image/png", err
+}
@@ -0,0 +1,146 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"testing"
+)
+
+// jpegWithOrientation encodes a w×h JPEG, red on the left half and blue on
+// the right, with an EXIF segment holding the given orientation.
+func jpegWithOrientation(t *testing.T, w, h, orientation int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{255, 0, 0, 255}
+			if x >= w/2 {
+				c = color.RGBA{0, 0, 255, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// little-endian TIFF header with one IFD entry: orientation, SHORT.
+	tiff := []byte("II*\x00\x08\x00\x00\x00\x01\x00")
+	entry := make([]byte, 12)
+	binary.LittleEndian.PutUint16(entry[0:], 0x0112)
+	binary.LittleEndian.PutUint16(entry[2:], 3)
+	binary.LittleEndian.PutUint32(entry[4:], 1)
+	binary.LittleEndian.PutUint16(entry[8:], uint16(orientation))
+	tiff = append(tiff, entry...)
+	tiff = append(tiff, 0, 0, 0, 0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	data := buf.Bytes()
+	return append(append([]byte{0xFF, 0xD8}, segment...), data[2:]...)
+}
+
+func TestProcessAppliesOrientationAndStripsEXIF(t *testing.T) {
+	data := jpegWithOrientation(t, 40, 20, 6)
+	if exifOrientation(data) != 6 {
+		t.Fatalf("expected orientation 6, got %d\n", exifOrientation(data))
+	}
+
+	img, err := Process(data)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if img.ContentType != "image/jpeg" || img.Width != 20 || img.Height != 40 {
+		t.Errorf("expected a 20x40 JPEG, got %dx%d %s\n", img.Width, img.Height, img.ContentType)
+	}
+	if bytes.Contains(img.Data, []byte("Exif")) {
+		t.Error("expected EXIF to be stripped\n")
+	}
+
+	// turned clockwise, the red left half ends up on top.
+	decoded, err := jpeg.Decode(bytes.NewReader(img.Data))
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	if r, _, b, _ := decoded.At(10, 5).RGBA(); r < b {
+		t.Errorf("expected red at the top after rotating, got r=%d b=%d\n", r, b)
+	}
+
+	if len(img.Thumbnail) == 0 || img.ThumbnailContentType != "image/jpeg" {
+		t.Errorf("expected a JPEG thumbnail, got %d bytes of %s\n", len(img.Thumbnail), img.ThumbnailContentType)
+	}
+}
+
+func TestProcessRejectsNonImages(t *testing.T) {
+	_, err := Process([]byte("<svg xmlns='http://www.w3.org/2000/svg'></svg>"))
+	if !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("expected ErrUnsupportedType, got %v\n", err)
+	}
+}
+
+// animatedGIF encodes a w×h GIF with the given number of 10×10 frames in
+// its top left corner.
+func animatedGIF(t *testing.T, w, h, frames int) []byte {
+	anim := &gif.GIF{Config: image.Config{ColorModel: color.Palette(palette.Plan9), Width: w, Height: h}}
+	for i := 0; i < frames; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, 10, 10), palette.Plan9)
+		frame.SetColorIndex(i%10, i%10, uint8(i))
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, 10)
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessGIF(t *testing.T) {
+	data := animatedGIF(t, 30, 20, 3)
+	if frames, err := gifFrames(data); err != nil || frames != 3 {
+		t.Fatalf("expected 3 frames, got %d, %v\n", frames, err)
+	}
+
+	img, err := Process(data)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// the size is the GIF's, not its first frame's.
+	if img.ContentType != "image/gif" || img.Width != 30 || img.Height != 20 {
+		t.Errorf("expected a 30x20 GIF, got %dx%d %s\n", img.Width, img.Height, img.ContentType)
+	}
+	anim, err := gif.DecodeAll(bytes.NewReader(img.Data))
+	if err != nil || len(anim.Image) != 3 {
+		t.Errorf("expected 3 frames to be kept, got %v\n", err)
+	}
+}
+
+func TestProcessRejectsGIFBombs(t *testing.T) {
+	// each frame is tiny in the file, but decodes to the full 2000x2000.
+	data := animatedGIF(t, 2000, 2000, MaxPixels/(2000*2000)+1)
+
+	_, err := Process(data)
+	if !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v\n", err)
+	}
+}
+
+func TestGIFFramesRejectsTruncated(t *testing.T) {
+	data := animatedGIF(t, 30, 20, 2)
+	if _, err := gifFrames(data[:len(data)-3]); err == nil {
+		t.Error("expected an error for a truncated GIF\n")
+	}
+}
@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "chirpy"
+
+// Registry holds every chirpy collector plus the standard Go runtime and
+// process collectors. we use our own registry instead of the prometheus
+// default one so tests and other packages can't register into it by
+// accident.
+var Registry = prometheus.NewRegistry()
+
+// HTTP metrics. route is the ServeMux pattern that matched the request
+// (e.g. "GET /api/chirps/{chirpID}"), never the raw URL path, so label
+// cardinality stays bounded.
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of HTTP requests by route and status.",
+	}, []string{"route", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "status"})
+
+	HTTPInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "Number of HTTP requests currently being served by route.",
+	}, []string{"route"})
+)
+
+// database metrics, labelled by sqlc query name.
+var (
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Database query latency by sqlc query name.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"query"})
+
+	DBQueryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_errors_total",
+		Help:      "Database queries that returned an error other than no rows.",
+	}, []string{"query"})
+)
+
+// business metrics.
+var (
+	FileserverHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "fileserver_hits_total",
+		Help:      "Requests served by the /app/ file server.",
+	})
+
+	ChirpsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "chirps_created_total",
+		Help:      "Chirps successfully created.",
+	})
+
+	Logins = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Successful logins.",
+	})
+
+	FailedLogins = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_failures_total",
+		Help:      "Login attempts rejected because of a wrong email or password.",
+	})
+
+	WebhookEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_events_total",
+		Help:      "Authenticated Polka webhook events by event type.",
+	}, []string{"event"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		HTTPInFlight,
+		DBQueryDuration,
+		DBQueryErrors,
+		FileserverHits,
+		ChirpsCreated,
+		Logins,
+		FailedLogins,
+		WebhookEvents,
+	)
+}
+
+// Handler serves every registered metric in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// QueryHook is a database.QueryHook that records query latency and errors.
+func QueryHook(ctx context.Context, name string) (context.Context, func(err error)) {
+	start := time.Now()
+
+	return ctx, func(err error) {
+		DBQueryDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+
+		// no rows is an expected outcome, e.g. looking up an unknown chirp.
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			DBQueryErrors.WithLabelValues(name).Inc()
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Package storage stores blobs (uploaded media for now) by key. the server
+// only talks to the Store interface; which implementation it gets depends
+// on the media_store setting.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	errInvalidKey = errors.New("invalid blob key")
+)
+
+// Store is a flat key-value store for blobs. keys look like paths
+// ("media/<id>") but implementations are free to store them however they
+// like.
+type Store interface {
+	// Put stores size bytes from r under key, replacing any existing blob.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+
+	// Get returns the blob stored under key, or ErrNotFound. the caller
+	// closes it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the blob under key. deleting a missing blob is not an
+	// error.
+	Delete(ctx context.Context, key string) error
+}
+
+// validKey rejects keys that could escape the store's root, e.g. on the
+// local filesystem.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
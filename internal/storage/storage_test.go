@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// testStore puts, gets and deletes a blob, the same way for every Store.
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	data := []byte("not really a picture")
+
+	if err := store.Put(ctx, "media/abc", bytes.NewReader(data), int64(len(data)), "image/png"); err != nil {
+		t.Fatalf("put: %v\n", err)
+	}
+
+	blob, err := store.Get(ctx, "media/abc")
+	if err != nil {
+		t.Fatalf("get: %v\n", err)
+	}
+	got, err := io.ReadAll(blob)
+	blob.Close()
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("expected %q, got %q (%v)\n", data, got, err)
+	}
+
+	if err := store.Delete(ctx, "media/abc"); err != nil {
+		t.Fatalf("delete: %v\n", err)
+	}
+	if _, err := store.Get(ctx, "media/abc"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v\n", err)
+	}
+	if err := store.Delete(ctx, "media/abc"); err != nil {
+		t.Errorf("expected deleting a missing blob to succeed, got %v\n", err)
+	}
+
+	if err := store.Put(ctx, "../escape", bytes.NewReader(data), int64(len(data)), "image/png"); err == nil {
+		t.Error("expected an error for a key outside the store\n")
+	}
+}
+
+func TestLocal(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	testStore(t, store)
+}
+
+// fakeS3 is just enough of the S3 API, with path-style URLs, for the
+// requests S3 makes. it stands in for a local MinIO.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[key] = body
+		w.Header().Set("ETag", `"etag"`)
+	case http.MethodGet, http.MethodHead:
+		body, ok := f.objects[key]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`)
+			return
+		}
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2025 00:00:00 GMT")
+		w.Write(body)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}}
+	server := httptest.NewTLSServer(fake)
+	defer server.Close()
+
+	client, err := minio.New(strings.TrimPrefix(server.URL, "https://"), &minio.Options{
+		Creds:     credentials.NewStaticV4("key", "secret", ""),
+		Secure:    true,
+		Region:    "us-east-1",
+		Transport: server.Client().Transport,
+	})
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	testStore(t, &S3{client: client, bucket: "chirpy"})
+
+	if len(fake.objects) != 0 {
+		t.Errorf("expected the bucket to be empty, got %d objects\n", len(fake.objects))
+	}
+}
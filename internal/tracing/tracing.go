@@ -0,0 +1,135 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/johndosdos/chirpy"
+
+// Tracer returns the tracer every chirpy span should be started from. it
+// reads the global provider on each call, so it works before and after
+// Setup.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup installs the global tracer provider and W3C trace-context
+// propagator. the exporter is picked from the standard OpenTelemetry
+// environment variables:
+//
+//   - OTEL_TRACES_EXPORTER: "otlp", "console" or "none". defaults to "otlp"
+//     when an OTLP endpoint is configured and "none" otherwise.
+//   - OTEL_EXPORTER_OTLP_PROTOCOL: "http/protobuf" (default) or "grpc".
+//   - OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_EXPORTER_OTLP_HEADERS, etc. are
+//     read by the exporters themselves.
+//
+// OTEL_SERVICE_NAME, OTEL_RESOURCE_ATTRIBUTES and OTEL_TRACES_SAMPLER are
+// honoured too. the returned function flushes and stops the provider.
+func Setup(ctx context.Context) (shutdown func(context.Context) error, err error) {
+	exporter, err := newExporter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return SetupWithExporter(ctx, exporter)
+}
+
+// SetupWithExporter is Setup with an explicit exporter, e.g. an in-memory
+// one in tests. a nil exporter disables exporting but still installs the
+// propagator, so trace context keeps flowing through the service.
+func SetupWithExporter(ctx context.Context, exporter sdktrace.SpanExporter) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName("chirpy")),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	kind := os.Getenv("OTEL_TRACES_EXPORTER")
+	if kind == "" {
+		kind = "none"
+		if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+			kind = "otlp"
+		}
+	}
+
+	switch kind {
+	case "none":
+		return nil, nil
+	case "console":
+		// stderr, so spans don't interleave with the JSON logs on stdout.
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	case "otlp":
+		protocol := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+		if protocol == "" {
+			protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+		}
+
+		switch protocol {
+		case "", "http/protobuf":
+			return otlptracehttp.New(ctx)
+		case "grpc":
+			return otlptracegrpc.New(ctx)
+		default:
+			return nil, fmt.Errorf("unsupported OTLP protocol: %q", protocol)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported traces exporter: %q", kind)
+	}
+}
+
+// QueryHook is a database.QueryHook that wraps every query in a client
+// span named after the sqlc query.
+func QueryHook(ctx context.Context, name string) (context.Context, func(err error)) {
+	ctx, span := Tracer().Start(ctx, "db."+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(name),
+		),
+	)
+
+	return ctx, func(err error) {
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQueryHook(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	shutdown, err := SetupWithExporter(context.Background(), exporter)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	ctx, parent := Tracer().Start(context.Background(), "GET /api/chirps/{chirpID}")
+
+	_, done := QueryHook(ctx, "GetChirp")
+	done(sql.ErrNoRows)
+
+	_, done = QueryHook(ctx, "AddChirp")
+	done(errors.New("connection refused"))
+
+	parent.End()
+
+	// flush the batcher into the exporter. shutting down would reset the
+	// in-memory exporter, so that has to wait until we're done.
+	provider := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	defer shutdown(context.Background())
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d\n", len(spans))
+	}
+
+	for _, span := range spans[:2] {
+		if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("span %s is not a child of the request span\n", span.Name)
+		}
+	}
+
+	if spans[0].Name != "db.GetChirp" || spans[0].Status.Code == codes.Error {
+		t.Errorf("no rows should not mark %s as failed\n", spans[0].Name)
+	}
+	if spans[1].Name != "db.AddChirp" || spans[1].Status.Code != codes.Error {
+		t.Errorf("expected %s to be marked as failed\n", spans[1].Name)
+	}
+}
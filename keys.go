@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/johndosdos/chirpy/internal/config"
+	"github.com/johndosdos/chirpy/internal/database"
+)
+
+const keysUsage = `usage: chirpy keys rotate [flags]
+
+  rotate  print a new JWT secret and optionally revoke every session
+`
+
+// runKeys implements "chirpy keys". the secret lives in the deployment's
+// configuration, not in the database, so rotating it is: generate a new one
+// here, put it in SECRET (or the config file) and restart. every access
+// token signed with the old secret stops working at that point.
+func runKeys(ctx context.Context, args []string, out io.Writer) error {
+	action, args, err := subcommand(args, keysUsage)
+	if err != nil {
+		return err
+	}
+	if action != "rotate" {
+		return fmt.Errorf("unknown keys command %q", action)
+	}
+
+	fset := flag.NewFlagSet("chirpy keys rotate", flag.ContinueOnError)
+	loader := config.NewLoader(fset)
+	size := fset.Int("bytes", 48, "number of random bytes in the new secret")
+	revoke := fset.Bool("revoke-sessions", false, "also revoke every refresh token, logging everyone out for good")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	// base64 makes 4 characters out of every 3 bytes.
+	if *size*4/3 < config.MinSecretLength {
+		return fmt.Errorf("--bytes must be at least %d", config.MinSecretLength*3/4)
+	}
+
+	key := make([]byte, *size)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(key)
+
+	// refresh tokens aren't signed with the secret, so they survive the
+	// rotation unless revoked.
+	if *revoke {
+		db, err := openDB(loader)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		revoked, err := database.New(db).RevokeAllRefreshTokens(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "revoked %d refresh token(s)\n", revoked)
+	}
+
+	// only the secret goes to out, so it can be piped into a secret store.
+	fmt.Fprintln(out, secret)
+	fmt.Fprintln(os.Stderr, "set SECRET to the new value and restart the server to finish the rotation")
+	return nil
+}
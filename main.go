@@ -1,85 +1,114 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
-	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
-	"github.com/johndosdos/chirpy/internal/app/chirpy"
-	"github.com/johndosdos/chirpy/internal/app/chirpy/handlers/admin"
-	"github.com/johndosdos/chirpy/internal/app/chirpy/handlers/api"
-	"github.com/johndosdos/chirpy/internal/database"
-	"github.com/joho/godotenv"
+	"github.com/johndosdos/chirpy/internal/config"
 
 	_ "github.com/lib/pq"
 )
 
-func main() {
-	// DATABASE INIT...
-
-	// Load .env file from project root. Our .env file contain sensitive
-	// keys and information. Please add to .gitignore
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("failed to load .env file: ", err)
-	}
+const usage = `usage: chirpy [command] [flags]
 
-	dbUrl := os.Getenv("DB_URL")
-	platform := os.Getenv("PLATFORM")
-	secret := os.Getenv("SECRET")
-	polkaKey := os.Getenv("POLKA_KEY")
+commands:
+  serve    start the HTTP server (the default)
+  migrate  apply, roll back or list database migrations
+  user     create, promote, suspend or unsuspend users
+  token    revoke refresh tokens
+  chirps   export chirps as JSON
+  keys     generate a new JWT secret and revoke sessions
 
-	db, err := sql.Open("postgres", dbUrl)
-	if err != nil {
-		log.Fatal("failed to initialize db: ", err)
-	}
+run "chirpy <command> -h" to see the flags of a command. every command
+reads the same configuration as the server, see internal/config.
+`
 
-	dbQueries := database.New(db)
+// errReported is returned by commands that already told the user what went
+// wrong, so main only has to set the exit code.
+var errReported = errors.New("error already reported")
 
-	// SERVER INIT...
-	mux := http.NewServeMux()
-	apiCfg := &chirpy.ApiConfig{
-		DB:       dbQueries,
-		Platform: platform,
-		Secret:   secret,
-		PolkaKey: polkaKey,
+func main() {
+	// SIGINT (ctrl+c) and SIGTERM (sent by most process managers on deploy)
+	// cancel ctx. the server starts a graceful shutdown, other commands
+	// abort whatever query is running.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := run(ctx, os.Args[1:])
+	stop()
+
+	switch {
+	case err == nil:
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(2)
+	case errors.Is(err, errReported):
+		os.Exit(1)
+	default:
+		fmt.Fprintln(os.Stderr, "chirpy:", err)
+		os.Exit(1)
 	}
+}
 
-	// check file server readiness.
-	admin.Check(mux)
-
-	// strip the prefix "/app/" from the URL path for proper routing.
-	// URL path != file path on the server.
-	fileServer := http.StripPrefix("/app/", http.FileServer(http.Dir("web/")))
-
-	mux.Handle("/app/", apiCfg.MiddlewareMetricsInc(fileServer))
-
-	mux.Handle("GET /admin/metrics", admin.GetHits(apiCfg))
-	mux.Handle("POST /admin/reset", admin.ResetMetrics(apiCfg))
-
-	mux.Handle("GET /api/chirps/{chirpID}", api.GetChirp(apiCfg))
-	mux.Handle("GET /api/chirps", api.GetChirps(apiCfg))
-	mux.Handle("POST /api/chirps", api.ProcessChirp(apiCfg))
-	mux.Handle("DELETE /api/chirps/{chirpID}", api.DeleteChirp(apiCfg))
-
-	mux.Handle("POST /api/users", api.CreateUser(apiCfg))
-	mux.Handle("PUT /api/users", api.UpdateUserInfo(apiCfg))
-
-	mux.Handle("POST /api/login", api.Login(apiCfg))
-
-	mux.Handle("POST /api/refresh", api.Refresh(apiCfg))
+// run picks the command from args. flags without a command, e.g.
+// "chirpy --addr :9000", start the server like before there were commands.
+func run(ctx context.Context, args []string) error {
+	command := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
 
-	mux.Handle("POST /api/revoke", api.Revoke(apiCfg))
+	switch command {
+	case "serve":
+		return runServe(ctx, args)
+	case "migrate":
+		return runMigrate(ctx, args, os.Stdout)
+	case "user":
+		return runUser(ctx, args, os.Stdout)
+	case "token":
+		return runToken(ctx, args, os.Stdout)
+	case "chirps":
+		return runChirps(ctx, args, os.Stdout)
+	case "keys":
+		return runKeys(ctx, args, os.Stdout)
+	case "help":
+		fmt.Fprint(os.Stdout, usage)
+		return nil
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
 
-	mux.Handle("POST /api/polka/webhooks", api.WebhookHandler(apiCfg))
+// subcommand splits "<action> [flags]" for commands like "user" that have
+// several actions, printing usage when there is no action.
+func subcommand(args []string, usage string) (string, []string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprint(os.Stderr, usage)
+		return "", nil, flag.ErrHelp
+	}
+	return args[0], args[1:], nil
+}
 
-	server := http.Server{
-		Addr:    ":8080",
-		Handler: mux,
+// openDB connects to the database for commands that only need db_url, so
+// the rest of the configuration is not validated. call it after the flag
+// set the loader was created with has been parsed.
+func openDB(loader *config.Loader) (*sql.DB, error) {
+	cfg, err := loader.Parse()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.DBURL == "" {
+		return nil, errors.New("db_url is required")
 	}
 
-	fmt.Println("Server starting at port 8080...")
-	log.Fatal(server.ListenAndServe())
+	db, err := sql.Open("postgres", cfg.DBURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize db: %w", err)
+	}
+	return db, nil
 }
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/johndosdos/chirpy/internal/config"
+	"github.com/johndosdos/chirpy/internal/database/migrate"
+	"github.com/pressly/goose/v3"
+)
+
+const migrateUsage = `usage: chirpy migrate <up|down|status> [flags]
+
+  up      apply all pending migrations
+  down    roll back the most recent migration
+  status  list migrations and whether they are applied
+`
+
+// runMigrate implements "chirpy migrate".
+func runMigrate(ctx context.Context, args []string, out io.Writer) error {
+	action, args, err := subcommand(args, migrateUsage)
+	if err != nil {
+		return err
+	}
+
+	fset := flag.NewFlagSet("chirpy migrate "+action, flag.ContinueOnError)
+	loader := config.NewLoader(fset)
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := openDB(loader)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	provider, err := migrate.NewProvider(db)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "up":
+		results, err := provider.Up(ctx)
+		for _, result := range results {
+			fmt.Fprintln(out, result)
+		}
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			fmt.Fprintln(out, "no pending migrations")
+		}
+	case "down":
+		result, err := provider.Down(ctx)
+		if result != nil {
+			fmt.Fprintln(out, result)
+		}
+		if err != nil {
+			return err
+		}
+	case "status":
+		statuses, err := provider.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tSTATE\tAPPLIED AT\tFILE")
+		for _, status := range statuses {
+			appliedAt := "-"
+			if status.State == goose.StateApplied {
+				appliedAt = status.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", status.Source.Version, status.State, appliedAt, status.Source.Path)
+		}
+		return tw.Flush()
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return fmt.Errorf("unknown migrate command %q", action)
+	}
+
+	return nil
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/handlers/admin"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/handlers/api"
+	"github.com/johndosdos/chirpy/internal/config"
+	"github.com/johndosdos/chirpy/internal/database"
+	"github.com/johndosdos/chirpy/internal/database/migrate"
+	"github.com/johndosdos/chirpy/internal/logging"
+	"github.com/johndosdos/chirpy/internal/metrics"
+	"github.com/johndosdos/chirpy/internal/storage"
+	"github.com/johndosdos/chirpy/internal/tracing"
+)
+
+// runServe implements "chirpy serve", which is also what runs when no
+// command is given.
+func runServe(ctx context.Context, args []string) error {
+	fset := flag.NewFlagSet("chirpy serve", flag.ContinueOnError)
+	loader := config.NewLoader(fset)
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	// see internal/config for where settings come from and their
+	// precedence. every invalid or missing setting is reported at once.
+	cfg, err := loader.Load()
+	if err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	// LOGGING INIT...
+
+	// everything, including the standard log package, goes through a
+	// JSON slog logger.
+	logger := logging.New(os.Stdout, cfg.LogLevel)
+	slog.SetDefault(logger)
+
+	// serve returns instead of exiting so that its deferred cleanup (closing
+	// the database, flushing traces) always happens.
+	if err := serve(ctx, cfg, logger); err != nil {
+		logger.Error("server stopped", "err", err)
+		return errReported
+	}
+	return nil
+}
+
+func serve(ctx context.Context, cfg config.Config, logger *slog.Logger) error {
+	// TRACING INIT...
+	//
+	// exporter and sampling are configured with the standard OTEL_*
+	// environment variables, see internal/tracing.
+	shutdownTracing, err := tracing.Setup(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+	defer func() {
+		// the signal context is already cancelled at this point.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("failed to flush traces", "err", err)
+		}
+	}()
+
+	// DATABASE INIT...
+	db, err := sql.Open("postgres", cfg.DBURL)
+	if err != nil {
+		return fmt.Errorf("failed to initialize db: %w", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			logger.Error("failed to close db", "err", err)
+		}
+	}()
+
+	if cfg.MigrateOnStart {
+		provider, err := migrate.NewProvider(db)
+		if err != nil {
+			return err
+		}
+
+		results, err := provider.Up(ctx)
+		for _, result := range results {
+			logger.Info("applied migration", "version", result.Source.Version, "file", result.Source.Path, "duration", result.Duration.String())
+		}
+		if err != nil {
+			return fmt.Errorf("failed to migrate database: %w", err)
+		}
+	}
+
+	// every query goes through metrics.QueryHook so that query timings
+	// show up in /metrics, and through tracing.QueryHook for spans.
+	queryHooks := []database.QueryHook{metrics.QueryHook, tracing.QueryHook}
+	dbQueries := database.New(database.Observe(db, queryHooks...))
+
+	mediaStore, err := newMediaStore(cfg.Media)
+	if err != nil {
+		return err
+	}
+
+	// SERVER INIT...
+	mux := http.NewServeMux()
+	apiCfg := &chirpy.ApiConfig{
+		DB:         dbQueries,
+		Conn:       db,
+		QueryHooks: queryHooks,
+		Platform:   cfg.Platform,
+		Secret:     cfg.Secret,
+		PolkaKey:   cfg.PolkaKey,
+		Workers:    chirpy.NewWorkers(),
+
+		DeletionGracePeriod: cfg.DeletionGracePeriod,
+
+		ChirpLimits: chirpy.ChirpLimits{
+			MaxLength:    cfg.Chirps.MaxLength,
+			MaxLengthRed: cfg.Chirps.MaxLengthRed,
+			URLLength:    cfg.Chirps.URLLength,
+			EditWindow:   cfg.Chirps.EditWindow,
+		},
+
+		Media:         mediaStore,
+		MediaMaxBytes: int64(cfg.Media.MaxBytes),
+	}
+
+	apiCfg.Workers.Go(chirpy.PurgeDeletedUsers(dbQueries, logger))
+	apiCfg.Workers.Go(chirpy.PublishScheduledChirps(dbQueries, logger))
+	apiCfg.Workers.Go(chirpy.CleanUpMedia(dbQueries, mediaStore, logger))
+
+	// liveness and readiness probes.
+	admin.Check(mux)
+	mux.Handle("GET /api/readyz", admin.Ready(apiCfg))
+
+	// strip the prefix "/app/" from the URL path for proper routing.
+	// URL path != file path on the server.
+	fileServer := http.StripPrefix("/app/", http.FileServer(http.Dir("web/")))
+
+	mux.Handle("/app/", apiCfg.MiddlewareMetricsInc(fileServer))
+
+	mux.Handle("GET /metrics", metrics.Handler())
+
+	mux.Handle("GET /admin/metrics", admin.GetHits(apiCfg))
+	mux.Handle("POST /admin/reset", admin.ResetAll(apiCfg))
+	mux.Handle("POST /admin/reset/{target}", admin.Reset(apiCfg))
+	mux.Handle("GET /admin/fixtures", admin.ListFixtures(apiCfg))
+	mux.Handle("POST /admin/fixtures/{name}", admin.LoadFixture(apiCfg))
+	mux.Handle("GET /admin/filter/rules", admin.ListFilterRules(apiCfg))
+	mux.Handle("POST /admin/filter/rules", admin.CreateFilterRule(apiCfg))
+	mux.Handle("PATCH /admin/filter/rules/{ruleID}", admin.UpdateFilterRule(apiCfg))
+	mux.Handle("DELETE /admin/filter/rules/{ruleID}", admin.DeleteFilterRule(apiCfg))
+	mux.Handle("GET /admin/filter/flags", admin.ListFilterFlags(apiCfg))
+	mux.Handle("GET /admin/moderation", admin.ListReports(apiCfg))
+	mux.Handle("GET /admin/moderation/{reportID}", admin.GetReport(apiCfg))
+	mux.Handle("POST /admin/moderation/{reportID}/claim", admin.ClaimReport(apiCfg))
+	mux.Handle("POST /admin/moderation/{reportID}/resolve", admin.ResolveReport(apiCfg))
+
+	mux.Handle("GET /api/chirps/{chirpID}", api.GetChirp(apiCfg))
+	mux.Handle("GET /api/chirps", api.GetChirps(apiCfg))
+	mux.Handle("POST /api/chirps", api.ProcessChirp(apiCfg))
+	mux.Handle("DELETE /api/chirps/{chirpID}", api.DeleteChirp(apiCfg))
+	mux.Handle("PATCH /api/chirps/{chirpID}", api.EditChirp(apiCfg))
+	mux.Handle("GET /api/chirps/{chirpID}/history", api.GetChirpHistory(apiCfg))
+	mux.Handle("GET /api/chirps/{chirpID}/quotes", api.GetChirpQuotes(apiCfg))
+	mux.Handle("POST /api/chirps/{chirpID}/report", api.ReportChirp(apiCfg))
+
+	mux.Handle("POST /api/media", api.UploadMedia(apiCfg))
+	mux.Handle("GET /api/media/{mediaID}", api.GetMedia(apiCfg))
+	mux.Handle("GET /api/media/{mediaID}/thumbnail", api.GetMediaThumbnail(apiCfg))
+
+	mux.Handle("POST /api/users", api.CreateUser(apiCfg))
+	mux.Handle("PUT /api/users", api.UpdateUserInfo(apiCfg))
+	mux.Handle("GET /api/users/me", api.GetMe(apiCfg))
+	mux.Handle("PATCH /api/users/me", api.PatchMe(apiCfg))
+	mux.Handle("DELETE /api/users/me", api.DeleteMe(apiCfg))
+	mux.Handle("GET /api/users/me/export", api.ExportMe(apiCfg))
+	mux.Handle("GET /api/users/{handle}", api.GetProfile(apiCfg))
+	mux.Handle("POST /api/users/{handle}/report", api.ReportUser(apiCfg))
+	mux.Handle("POST /api/users/{handle}/block", api.Block(apiCfg))
+	mux.Handle("DELETE /api/users/{handle}/block", api.Unblock(apiCfg))
+	mux.Handle("POST /api/users/{handle}/mute", api.Mute(apiCfg))
+	mux.Handle("DELETE /api/users/{handle}/mute", api.Unmute(apiCfg))
+	mux.Handle("GET /api/users/me/blocks", api.GetBlocks(apiCfg))
+	mux.Handle("GET /api/users/me/mutes", api.GetMutes(apiCfg))
+	mux.Handle("GET /api/users/me/muted-words", api.GetMutedWords(apiCfg))
+	mux.Handle("POST /api/users/me/muted-words", api.CreateMutedWord(apiCfg))
+	mux.Handle("DELETE /api/users/me/muted-words/{mutedWordID}", api.DeleteMutedWord(apiCfg))
+	mux.Handle("POST /api/chirps/{chirpID}/bookmark", api.BookmarkChirp(apiCfg))
+	mux.Handle("DELETE /api/chirps/{chirpID}/bookmark", api.UnbookmarkChirp(apiCfg))
+	mux.Handle("GET /api/bookmarks", api.GetBookmarks(apiCfg))
+	mux.Handle("GET /api/bookmarks/folders", api.GetBookmarkFolders(apiCfg))
+	mux.Handle("POST /api/bookmarks/folders", api.CreateBookmarkFolder(apiCfg))
+	mux.Handle("DELETE /api/bookmarks/folders/{folderID}", api.DeleteBookmarkFolder(apiCfg))
+	mux.Handle("GET /api/drafts", api.GetDrafts(apiCfg))
+	mux.Handle("POST /api/drafts", api.CreateDraft(apiCfg))
+	mux.Handle("GET /api/drafts/{draftID}", api.GetDraft(apiCfg))
+	mux.Handle("PATCH /api/drafts/{draftID}", api.UpdateDraft(apiCfg))
+	mux.Handle("DELETE /api/drafts/{draftID}", api.DeleteDraft(apiCfg))
+	mux.Handle("POST /api/drafts/{draftID}/publish", api.PublishDraft(apiCfg))
+	mux.Handle("GET /api/users/me/scheduled-chirps", api.GetScheduledChirps(apiCfg))
+	mux.Handle("PATCH /api/users/me/scheduled-chirps/{chirpID}", api.RescheduleChirp(apiCfg))
+	mux.Handle("DELETE /api/users/me/scheduled-chirps/{chirpID}", api.CancelScheduledChirp(apiCfg))
+
+	mux.Handle("GET /api/notifications", api.GetNotifications(apiCfg))
+	mux.Handle("POST /api/notifications/read", api.ReadNotifications(apiCfg))
+
+	mux.Handle("POST /api/login", api.Login(apiCfg))
+
+	mux.Handle("POST /api/refresh", api.Refresh(apiCfg))
+
+	mux.Handle("POST /api/revoke", api.Revoke(apiCfg))
+
+	mux.Handle("POST /api/polka/webhooks", api.WebhookHandler(apiCfg))
+
+	server := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           chirpy.MiddlewareTracing(mux, chirpy.MiddlewareLogging(logger, chirpy.MiddlewareInstrument(mux))),
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+	}
+
+	// Shutdown doesn't wait for hijacked or long-lived connections, so tell
+	// the workers serving them to wrap up as soon as draining starts.
+	server.RegisterOnShutdown(func() {
+		apiCfg.Workers.Stop(context.Background())
+	})
+
+	// listen before reporting ready, so that a bad address fails right away.
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return err
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("server starting", "addr", listener.Addr().String(), "tls", cfg.Server.TLSCertFile != "")
+		if cfg.Server.TLSCertFile != "" {
+			serveErr <- server.ServeTLS(listener, cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+		} else {
+			serveErr <- server.Serve(listener)
+		}
+	}()
+	apiCfg.Ready.Store(true)
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	// report not ready first and keep serving for a little while, so load
+	// balancers notice before we stop accepting connections.
+	apiCfg.Ready.Store(false)
+	if cfg.Server.ShutdownDelay > 0 {
+		logger.Info("shutting down, waiting before draining", "delay", cfg.Server.ShutdownDelay.String())
+		time.Sleep(cfg.Server.ShutdownDelay)
+	}
+
+	logger.Info("shutting down, draining in-flight requests", "timeout", cfg.Server.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to drain connections: %w", err)
+	}
+	if err := apiCfg.Workers.Stop(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to stop background workers: %w", err)
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	logger.Info("server stopped")
+	return nil
+}
+
+// newMediaStore opens the blob store picked by the media_store setting.
+func newMediaStore(cfg config.Media) (storage.Store, error) {
+	if cfg.Store == "s3" {
+		return storage.NewS3(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			Region:    cfg.S3Region,
+			UseSSL:    cfg.S3UseSSL,
+		})
+	}
+	return storage.NewLocal(cfg.Dir)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/johndosdos/chirpy/internal/config"
+	"github.com/johndosdos/chirpy/internal/database"
+)
+
+const tokenUsage = `usage: chirpy token revoke [flags] [refresh token]
+
+  revoke  revoke one refresh token, every token of a user (--email) or
+          every token there is (--all)
+`
+
+// runToken implements "chirpy token". access tokens are JWTs and can't be
+// revoked individually, they expire after an hour. to invalidate them all,
+// rotate the secret (see "chirpy keys rotate").
+func runToken(ctx context.Context, args []string, out io.Writer) error {
+	action, args, err := subcommand(args, tokenUsage)
+	if err != nil {
+		return err
+	}
+	if action != "revoke" {
+		return fmt.Errorf("unknown token command %q", action)
+	}
+
+	fset := flag.NewFlagSet("chirpy token revoke", flag.ContinueOnError)
+	loader := config.NewLoader(fset)
+	email := fset.String("email", "", "revoke every refresh token of this user")
+	all := fset.Bool("all", false, "revoke every refresh token of every user")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	// exactly one of a token, --email or --all.
+	given := 0
+	for _, ok := range []bool{fset.NArg() > 0, *email != "", *all} {
+		if ok {
+			given++
+		}
+	}
+	if given != 1 || fset.NArg() > 1 {
+		fmt.Fprint(fset.Output(), tokenUsage)
+		return errors.New("pass exactly one of a refresh token, --email or --all")
+	}
+
+	db, err := openDB(loader)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	queries := database.New(db)
+
+	var revoked int64
+	switch {
+	case *all:
+		revoked, err = queries.RevokeAllRefreshTokens(ctx)
+	case *email != "":
+		var user database.User
+		if user, err = queries.GetUserByEmail(ctx, *email); err != nil {
+			return fmt.Errorf("no user with email %q: %w", *email, err)
+		}
+		revoked, err = queries.RevokeUserRefreshTokens(ctx, user.ID)
+	default:
+		revoked, err = queries.RevokeRefreshToken(ctx, fset.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "revoked %d refresh token(s)\n", revoked)
+	return nil
+}
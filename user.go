@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/johndosdos/chirpy/internal/app/chirpy"
+	"github.com/johndosdos/chirpy/internal/app/chirpy/validate"
+	"github.com/johndosdos/chirpy/internal/auth"
+	"github.com/johndosdos/chirpy/internal/config"
+	"github.com/johndosdos/chirpy/internal/database"
+)
+
+const userUsage = `usage: chirpy user <create|promote|suspend|unsuspend> [flags]
+
+  create     create a user, e.g. the first admin
+  promote    change a user's role to moderator or admin (or back to user)
+  suspend    stop a user from logging in and revoke their sessions
+  unsuspend  lift a suspension
+`
+
+// roles a user can have, see the users.role check constraint.
+var roles = map[string]bool{"user": true, "moderator": true, "admin": true}
+
+// runUser implements "chirpy user". users are looked up by email, since
+// that's what operators get from support requests.
+func runUser(ctx context.Context, args []string, out io.Writer) error {
+	action, args, err := subcommand(args, userUsage)
+	if err != nil {
+		return err
+	}
+
+	fset := flag.NewFlagSet("chirpy user "+action, flag.ContinueOnError)
+	loader := config.NewLoader(fset)
+	email := fset.String("email", "", "email of the user (required)")
+
+	var password, role, handle *string
+	var red *bool
+	switch action {
+	case "create":
+		password = fset.String("password", "", "password of the new user (required)")
+		handle = fset.String("handle", "", "public handle, random if not given")
+		role = fset.String("role", "user", "user, moderator or admin")
+		red = fset.Bool("red", false, "give the user Chirpy Red")
+	case "promote":
+		role = fset.String("role", "moderator", "user, moderator or admin")
+	case "suspend", "unsuspend":
+	default:
+		return fmt.Errorf("unknown user command %q", action)
+	}
+
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return errors.New("--email is required")
+	}
+	if role != nil && !roles[*role] {
+		return fmt.Errorf(`--role must be "user", "moderator" or "admin", got %q`, *role)
+	}
+
+	db, err := openDB(loader)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	queries := database.New(db)
+
+	var user database.User
+	switch action {
+	case "create":
+		user, err = createUser(ctx, db, *email, *password, *handle, *role, *red)
+	case "promote":
+		user, err = queries.GetUserByEmail(ctx, *email)
+		if err == nil {
+			user, err = queries.SetUserRole(ctx, database.SetUserRoleParams{Role: *role, ID: user.ID})
+		}
+	case "suspend":
+		user, err = suspendUser(ctx, db, *email)
+	case "unsuspend":
+		user, err = queries.GetUserByEmail(ctx, *email)
+		if err == nil {
+			user, err = queries.UnsuspendUser(ctx, user.ID)
+		}
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no user with email %q", *email)
+	}
+	if err != nil {
+		return err
+	}
+
+	return printUser(out, user)
+}
+
+// createUser goes through the same password hashing as POST /api/users,
+// and sets the role and membership in the same transaction.
+func createUser(ctx context.Context, db *sql.DB, email, password, handle, role string, red bool) (database.User, error) {
+	// same rules as the API.
+	var v validate.Validator
+	v.Required("--password", password)
+	v.Email("--email", email)
+	v.Password("--password", password)
+	v.Handle("--handle", handle)
+	if !v.Valid() {
+		var errs []error
+		for _, err := range v.Errors {
+			errs = append(errs, fmt.Errorf("%s %s", err.Field, err.Message))
+		}
+		return database.User{}, errors.Join(errs...)
+	}
+
+	hash, err := auth.HashPassword(ctx, password)
+	if err != nil {
+		return database.User{}, err
+	}
+
+	if handle != "" {
+		return insertUser(ctx, db, email, hash, handle, role, red)
+	}
+
+	// a generated handle that's taken gets another try, see POST /api/users.
+	for attempt := 1; ; attempt++ {
+		handle, err := chirpy.DefaultHandle()
+		if err != nil {
+			return database.User{}, err
+		}
+
+		user, err := insertUser(ctx, db, email, hash, handle, role, red)
+		if attempt < chirpy.DefaultHandleAttempts && database.IsUniqueViolation(err, database.UsersHandleKey) {
+			continue
+		}
+		return user, err
+	}
+}
+
+// insertUser creates the user with its role and membership in one
+// transaction.
+func insertUser(ctx context.Context, db *sql.DB, email, hash, handle, role string, red bool) (database.User, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return database.User{}, err
+	}
+	defer tx.Rollback()
+
+	queries := database.New(tx)
+
+	user, err := queries.CreateUser(ctx, database.CreateUserParams{
+		Email:          email,
+		HashedPassword: hash,
+		Handle:         handle,
+	})
+	if err != nil {
+		return database.User{}, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	if role != user.Role {
+		if user, err = queries.SetUserRole(ctx, database.SetUserRoleParams{Role: role, ID: user.ID}); err != nil {
+			return database.User{}, err
+		}
+	}
+
+	if red {
+		if user, err = queries.UpgradeUser(ctx, user.ID); err != nil {
+			return database.User{}, err
+		}
+	}
+
+	return user, tx.Commit()
+}
+
+// suspendUser marks the user as suspended and revokes their refresh tokens,
+// so they're logged out once their current access token expires.
+func suspendUser(ctx context.Context, db *sql.DB, email string) (database.User, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return database.User{}, err
+	}
+	defer tx.Rollback()
+
+	queries := database.New(tx)
+
+	user, err := queries.GetUserByEmail(ctx, email)
+	if err != nil {
+		return database.User{}, err
+	}
+
+	if user, err = queries.SuspendUser(ctx, user.ID); err != nil {
+		return database.User{}, err
+	}
+
+	if _, err := queries.RevokeUserRefreshTokens(ctx, user.ID); err != nil {
+		return database.User{}, err
+	}
+
+	return user, tx.Commit()
+}
+
+// printUser writes the user as JSON, without the password hash.
+func printUser(out io.Writer, user database.User) error {
+	type output struct {
+		ID          uuid.UUID  `json:"id"`
+		CreatedAt   time.Time  `json:"created_at"`
+		UpdatedAt   time.Time  `json:"updated_at"`
+		Email       string     `json:"email"`
+		Handle      string     `json:"handle"`
+		IsChirpyRed bool       `json:"is_chirpy_red"`
+		Role        string     `json:"role"`
+		SuspendedAt *time.Time `json:"suspended_at"`
+	}
+
+	o := output{
+		ID:          user.ID,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+		Email:       user.Email,
+		Handle:      user.Handle,
+		IsChirpyRed: user.IsChirpyRed,
+		Role:        user.Role,
+	}
+	if user.SuspendedAt.Valid {
+		o.SuspendedAt = &user.SuspendedAt.Time
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(o)
+}